@@ -32,6 +32,14 @@ It wraps vite build command.
 
 Ensure to edit env.production and .sveltin.toml files to reflect
 your production environment.
+
+Use --env to build with the base URL of a named deploy environment (default: production).
+
+Use --precompress to write the gzip (.gz) and brotli (.br) variants of the text files (html, css, js,
+json, xml, svg, ...) within the "kit.adapter.pages" and "kit.adapter.assets" folders. Files smaller than
+--precompress-min-size (default: 1 KiB) are skipped. Use --precompress-htaccess to also add the Apache
+rules serving them with the right Content-Type and Content-Encoding headers to the .htaccess file within
+the pages folder.
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
@@ -54,13 +62,26 @@ func RunBuildCmd(cmd *cobra.Command, args []string) {
 	npmClient, err := utils.RetrievePackageManagerFromPkgJSON(cfg.fs, pathToPkgFile)
 	utils.ExitIfError(err)
 
-	os.Setenv("VITE_PUBLIC_BASE_PATH", cfg.prodData.BaseURL)
+	env, err := loadDeployEnv(withEnv)
+	utils.ExitIfError(err)
+
+	os.Setenv("VITE_PUBLIC_BASE_PATH", env.data.BaseURL)
 	err = helpers.RunPMCommand(npmClient.Name, "build", "", nil, false)
 	utils.ExitIfError(err)
 
+	if isPrecompress || isPrecompressHtaccess {
+		utils.ExitIfError(precompressBuild())
+	}
+
 	cfg.log.Success("Done\n")
 }
 
+func buildCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withEnv, "env", DefaultDeployEnv, "name of the deploy environment to read the base URL from")
+	precompressCmdFlags(cmd)
+}
+
 func init() {
+	buildCmdFlags(buildCmd)
 	rootCmd.AddCommand(buildCmd)
 }
@@ -0,0 +1,104 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/config"
+	"github.com/sveltinio/sveltin/helpers"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	isPrecompress         bool
+	isPrecompressHtaccess bool
+	precompressMinSize    string
+)
+
+// precompressCmdFlags adds the flags shared by the build and deploy commands to write the precompressed files.
+func precompressCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&isPrecompress, "precompress", false, "write the gzip and brotli variants for the text files within the build folders")
+	cmd.Flags().StringVar(&precompressMinSize, "precompress-min-size", utils.ToHumanBytes(helpers.DefaultPrecompressMinSize), "size below which files are not precompressed (e.g. 512B, 2KB)")
+	cmd.Flags().BoolVar(&isPrecompressHtaccess, "precompress-htaccess", false, "add the Apache rules serving the precompressed files to the .htaccess file within the pages folder (implies --precompress)")
+}
+
+// precompressBuild writes the gzip and brotli variants for the text files within the "kit.adapter.pages"
+// and "kit.adapter.assets" folders. With --precompress-htaccess, the rules serving them are added to
+// the .htaccess file within the pages folder.
+func precompressBuild() error {
+	minSize, err := utils.ParseHumanBytes(precompressMinSize)
+	if err != nil {
+		return fmt.Errorf("invalid --precompress-min-size value '%s', got error '%s'", precompressMinSize, err.Error())
+	}
+
+	adapter := cfg.projectSettings.SvelteKit.Adapter
+	folders := []string{adapter.Pages}
+	if adapter.Assets != adapter.Pages {
+		folders = append(folders, adapter.Assets)
+	}
+
+	cfg.log.Infof("Precompressing the text files of at least %s within '%s'", utils.ToHumanBytes(minSize), strings.Join(folders, "', '"))
+	var count int
+	var size, gzipSize, brotliSize int64
+	for _, folder := range folders {
+		files, err := helpers.Precompress(cfg.fs, folder, minSize)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			count++
+			size += file.Size
+			gzipSize += variantSize(file.GzipSize, file.Size)
+			brotliSize += variantSize(file.BrotliSize, file.Size)
+		}
+	}
+	if count == 0 {
+		cfg.log.Info("No files to precompress")
+	} else {
+		cfg.log.Successf("%d files precompressed, %s down to %s with gzip and %s with brotli", count,
+			utils.ToHumanBytes(size), utils.ToHumanBytes(gzipSize), utils.ToHumanBytes(brotliSize))
+	}
+
+	if isPrecompressHtaccess {
+		return writePrecompressHtaccess(adapter.Pages)
+	}
+	return nil
+}
+
+// writePrecompressHtaccess adds the rules serving the precompressed files to the .htaccess file within the folder.
+func writePrecompressHtaccess(folder string) error {
+	contentTypes := make(map[string]string, len(helpers.PrecompressContentTypes))
+	for ext, contentType := range helpers.PrecompressContentTypes {
+		contentTypes[strings.TrimPrefix(ext, ".")] = contentType
+	}
+	data := &config.TemplateData{
+		Precompress: &tpltypes.PrecompressData{ContentTypes: contentTypes},
+	}
+	block := helpers.BuildTemplate(resources.DeployFilesMap["precompress_htaccess"], nil, data).Run(&resources.SveltinTemplatesFS)
+
+	pathToHtaccess := filepath.Join(folder, ".htaccess")
+	if err := helpers.WriteHtaccessBlock(cfg.fs, pathToHtaccess, block); err != nil {
+		return err
+	}
+	cfg.log.Infof("The rules serving the precompressed files have been added to '%s'", pathToHtaccess)
+	return nil
+}
+
+// variantSize returns the size served for a file, the variant one if it has been written.
+func variantSize(variant, original int64) int64 {
+	if variant == 0 {
+		return original
+	}
+	return variant
+}
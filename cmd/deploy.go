@@ -8,7 +8,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/sveltinio/prompti/confirm"
 	"github.com/sveltinio/sveltin/common"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
 	"github.com/sveltinio/sveltin/internal/ftpfs"
 	"github.com/sveltinio/sveltin/internal/markup"
 	"github.com/sveltinio/sveltin/internal/tpltypes"
@@ -36,17 +39,39 @@ const (
 )
 
 var (
-	isDryRun        bool
-	isBackup        bool
-	withExclude     []string
-	withExcludeFile string
+	isDryRun          bool
+	isBackup          bool
+	withExclude       []string
+	withExcludeFile   string
+	isFullDeploy      bool
+	uploadConcurrency int
+	withEnv           string
+	isListEnvs        bool
+	isAutoConfirm     bool
+	outputFormat      string
+	isAtomic          bool
+	isResume          bool
+	isVerify          bool
+	deployTarget      string
+	ignoreRules       *ftpfs.IgnoreRules
 )
 
 var deployCmd = &cobra.Command{
 	Use:     "deploy",
 	Aliases: []string{"publish"},
-	Short:   "Deploy your website over FTP",
-	Long: `Command used to deploy the project on your hosting platform over FTP.
+	Short:   "Deploy your website over FTP, SFTP or to an S3 bucket",
+	Long: `Command used to deploy the project on your hosting platform over FTP or SFTP, or to an S3 compatible bucket.
+
+The protocol and the connection are set by the .env.production file (DEPLOY_PROTOCOL, default: ftp)
+or by the deploy environment selected with --env. Use --target git to push the build to a git branch.
+
+Only new and changed files are uploaded, as recorded by the manifest of the previous deploy on the
+remote folder. The remote folder is locked while deploying and its content is backed up locally.
+
+When not running in a terminal, plain logs are printed and --yes is required. Exit codes: 1 generic
+error, 3 connection, 4 authentication, 5 transfer, 6 verification failed, 7 remote folder locked.
+
+See the README for the full list of variables and settings.
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
@@ -63,124 +88,255 @@ func DeployCmdRun(cmd *cobra.Command, args []string) {
 	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
 	isValidProject(true)
 
-	cfg.log.Plain(markup.H1("Deploy your website to the FTP server"))
-
-	// if --excludeFile is set, combines its lines with values from the --exclude flag.
-	if len(withExcludeFile) != 0 {
-		lines, err := common.ReadFileLineByLine(cfg.fs, withExcludeFile)
-		utils.ExitIfError(err)
-		withExclude = common.Union(withExclude, lines)
+	if isListEnvs {
+		showDeployEnvs()
+		return
 	}
 
-	ftpConnectionConfig := newFTPConnectionConfig(cfg.prodData)
-
-	ftpConn := ftpfs.NewFTPServerConnection(ftpConnectionConfig)
-	ftpConn.SetRootFolder(cfg.prodData.FTPServerFolder)
-	ftpConn.SetLogger(cfg.log)
-
-	err := ftpfs.DialAction(ftpConn).Run()
+	isPlain, err := setupDeployOutput()
 	utils.ExitIfError(err)
 
-	err = ftpfs.LoginAction(ftpConn).Run()
-	utils.ExitIfError(err)
+	cfg.log.Plain(markup.H1("Deploy your website to the remote server"))
 
-	// prevent the remote FTP server to close the idle connection
-	noOpAction := ftpfs.IdleAction(ftpConn)
-	err = noOpAction.Run()
-	utils.ExitIfError(err)
-
-	feedbacks.ShowDeployCommandWarningMessages(isBackup)
+	report := newDeployReport()
+	err = runDeploy(cmd, report, isPlain)
+	exitWithReport(report, err)
+}
 
-	if isDryRun {
-		feedbacks.ShowDryRunMessage()
+// runDeploy performs the deploy and fills the report with its outcome.
+func runDeploy(cmd *cobra.Command, report *deployReport, isPlain bool) error {
+	if err := validateDeployFlags(); err != nil {
+		return err
 	}
 
-	isConfirm, err := confirm.Run(&confirm.Config{Question: "Continue?"})
-	utils.ExitIfError(err)
+	if err := loadChmodRules(); err != nil {
+		return err
+	}
 
-	if isConfirm {
-		// create a local tar archive as backup for the remote folder content
-		if isBackup {
-			backupsFolderPath := filepath.Join(cfg.pathMaker.GetRootFolder(), BackupsFolder)
-			utils.ExitIfError(common.MkDir(cfg.fs, backupsFolderPath))
-			pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
-			projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
-			utils.ExitIfError(err)
-			err = ftpfs.BackupAction(ftpConn, cfg.fs, filepath.Join(backupsFolderPath, projectName), isDryRun).Run()
-			utils.ExitIfError(err)
+	env, err := loadDeployEnv(withEnv)
+	if err != nil {
+		return err
+	}
+	applyDeployEnvFlags(cmd, env)
+	if isPrecompress || isPrecompressHtaccess {
+		if err := precompressBuild(); err != nil {
+			return err
 		}
+	}
+	if deployTarget == DeployTargetGit {
+		report.Environment = env.name
+		report.DryRun = isDryRun
+		return deployToGit(env, report)
+	}
+	if isAtomic && strings.EqualFold(env.data.DeployProtocol, ftpfs.ProtocolS3) {
+		return errors.New("--atomic cannot be used with the s3 protocol, objects can not be renamed on a bucket")
+	}
+	retention, err := backupRetention(cfg.projectSettings.Deploy.Backups)
+	if err != nil {
+		return err
+	}
+	report.Environment = env.name
+	report.DryRun = isDryRun
+	report.Resumed = isResume
+	cfg.log.Infof("Deploying to the '%s' environment (%s)", env.name, env.data.BaseURL)
+
+	remoteConn, err := newRemoteServer(env, isPlain)
+	if err != nil {
+		return err
+	}
 
-		// delete content from the remote folder with exclude list
-		cfg.log.Important(fmt.Sprintf("If present, the following files will not be deleted from the remote folder: %s", strings.Join(withExclude, ", ")))
-		err = ftpfs.DeleteAllAction(ftpConn, withExclude, isDryRun).Run()
-		utils.ExitIfError(err)
-
-		// create and update content from "kit.adapter.pages" folder
-		kitPagesFolder := cfg.projectSettings.SvelteKit.Adapter.Pages
-		pagesFoldersList, err := walkLocal(cfg.fs, EntryTypeFolder, kitPagesFolder, true)
-		utils.ExitIfError(err)
+	if err := connectRemoteServer(remoteConn); err != nil {
+		return err
+	}
+	isDisconnected := false
+	defer disconnectRemoteServer(remoteConn, &isDisconnected)
+
+	// prevent concurrent deploys to the same remote folder, the lock is taken before reading
+	// the remote manifest so that no other deploy can change it while this one is running
+	lock, err := acquireDeployLock(remoteConn, env.name)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
 
-		cfg.log.Infof("Creating remote folders structure for '%s'", kitPagesFolder)
-		err = ftpfs.MakeDirsAction(ftpConn, pagesFoldersList, isDryRun).Run()
-		utils.ExitIfError(err)
+	// compute the manifest for the "kit.adapter.pages" and "kit.adapter.assets" folders content
+	deployFiles, err := newDeployFiles(cfg.projectSettings.SvelteKit.Adapter, ignoreRules)
+	if err != nil {
+		return err
+	}
+	showIgnoredFiles(deployFiles.ignored)
+	for file := range deployFiles.ignored {
+		report.Skipped = append(report.Skipped, file)
+	}
+	localManifest, err := ftpfs.NewManifest(cfg.fs, deployFiles.toMap())
+	if err != nil {
+		return err
+	}
 
-		// prevent the remote FTP server to close the idle connection
-		err = noOpAction.Run()
-		utils.ExitIfError(err)
+	// a manifest on the remote folder means an incremental deploy can be done
+	var remoteManifest ftpfs.Manifest
+	if !isFullDeploy && !isAtomic {
+		if err := ftpfs.ReadManifestAction(remoteConn, &remoteManifest).Run(); err != nil {
+			return withExitCode(ExitCodeTransfer, err)
+		}
+	}
+	isIncremental := remoteManifest != nil
+
+	// the journal keeps track of the uploaded files to resume an interrupted deploy
+	journalPath := filepath.Join(cfg.pathMaker.GetRootFolder(), SveltinFolder, ftpfs.JournalFilename)
+	var journal *ftpfs.Journal
+	if isResume {
+		if journal, err = loadDeployJournal(journalPath, env.name, localManifest); err != nil {
+			return err
+		}
+		cfg.log.Infof("Resuming the interrupted deploy, %d files already uploaded", len(journal.Confirmed()))
+	} else if exists, _ := afero.Exists(cfg.fs, journalPath); exists {
+		cfg.log.Important("The previous deploy has been interrupted. Use --resume to continue it instead of starting over")
+	}
 
-		cfg.log.Infof("Uploading files to the remote folder '%s'", kitPagesFolder)
-		pagesFilesList, err := walkLocal(cfg.fs, EntryTypeFile, kitPagesFolder, true)
-		utils.ExitIfError(err)
+	switch {
+	case isAtomic:
+		report.Mode = "atomic"
+	case isIncremental:
+		report.Mode = "incremental"
+	default:
+		report.Mode = "full"
+	}
 
-		err = ftpfs.UploadAction(ftpConn, cfg.fs, kitPagesFolder, pagesFilesList, true, isDryRun).Run()
-		utils.ExitIfError(err)
+	// the maintenance page is shown while a full deploy replaces the remote folder content
+	var maintenance *maintenancePage
+	if isResume {
+		if maintenance, err = loadMaintenanceState(); err != nil {
+			return err
+		}
+	} else if isMaintenanceEnabled(cmd) {
+		if report.Mode == "full" {
+			if maintenance, err = newMaintenancePage(env.data.BaseURL); err != nil {
+				return err
+			}
+		} else {
+			cfg.log.Importantf("The maintenance page is only shown by full deploys, it is skipped by %s ones", report.Mode)
+		}
+	}
 
-		// prevent the remote FTP server to close the idle connection
-		err = noOpAction.Run()
-		utils.ExitIfError(err)
+	if outputFormat == OutputText {
+		feedbacks.ShowDeployCommandWarningMessages(isBackup, isIncremental, isAtomic)
+		if isDryRun {
+			feedbacks.ShowDryRunMessage()
+		}
+	}
 
-		/**
-		* Check if pages and assets props for adapter-static are differents.
-		* If true, upload the entire kit.adapter.assets folder.
-		**/
-		kitAssetsFolder := cfg.projectSettings.SvelteKit.Adapter.Assets
-		if kitPagesFolder != kitAssetsFolder {
-			assetsFoldersList, err := walkLocal(cfg.fs, EntryTypeFolder, kitAssetsFolder, false)
-			utils.ExitIfError(err)
+	isConfirm, err := confirmDeploy()
+	if err != nil || !isConfirm {
+		return err
+	}
 
-			cfg.log.Infof("Creating remote folders structure for '%s'", kitAssetsFolder)
-			err = ftpfs.MakeDirsAction(ftpConn, assetsFoldersList, isDryRun).Run()
-			utils.ExitIfError(err)
+	// create a local tar archive as backup for the remote folder content
+	if isBackup && !isResume {
+		backupsPath, err := backupsFolderPath(env.name)
+		if err != nil {
+			return err
+		}
+		if err := common.MkDir(cfg.fs, backupsPath); err != nil {
+			return err
+		}
+		pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+		projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+		if err != nil {
+			return err
+		}
+		err = ftpfs.BackupAction(remoteConn, cfg.fs, filepath.Join(backupsPath, projectName), isDryRun).Run()
+		if err != nil {
+			return withExitCode(ExitCodeTransfer, err)
+		}
+		// rotate the backup archives as set by the retention policies
+		if err := pruneBackups(env.name, retention, isDryRun); err != nil {
+			return err
+		}
+	}
 
-			// prevent the remote FTP server to close the idle connection
-			err = noOpAction.Run()
-			utils.ExitIfError(err)
+	if !isDryRun && !isResume && !isAtomic {
+		if journal, err = ftpfs.NewJournal(cfg.fs, journalPath, env.name, localManifest); err != nil {
+			return err
+		}
+	}
+	if !isDryRun {
+		remoteConn.SetJournal(journal)
+	}
+	confirmedBefore := journal.Confirmed()
+
+	switch {
+	case isAtomic:
+		err = deployAtomic(remoteConn, deployFiles, localManifest, report)
+	case isIncremental:
+		diff := localManifest.Diff(remoteManifest)
+		err = deployChanges(remoteConn, deployFiles, diff, localManifest, remoteManifest, journal, report)
+		if err == nil && outputFormat == OutputText {
+			feedbacks.ShowDeploySummary(diff.Added, diff.Changed, diff.Removed)
+		}
+	default:
+		err = deployAll(remoteConn, deployFiles, journal, maintenance, report)
+	}
 
-			cfg.log.Infof("Uploading files to the remote folder '%s'", kitAssetsFolder)
-			assetsFilesList, err := walkLocal(cfg.fs, EntryTypeFile, kitAssetsFolder, false)
-			utils.ExitIfError(err)
+	// store the manifest on the remote folder for the next incremental deploy
+	if err == nil && !isAtomic {
+		err = ftpfs.WriteManifestAction(remoteConn, localManifest, isDryRun).Run()
+	}
+	if err != nil {
+		if journal != nil {
+			report.Uploaded = uploadedSince(journal, confirmedBefore)
+			cfg.log.Important("The deploy has been interrupted. Run it again with --resume to continue")
+		}
+		return withExitCode(ExitCodeTransfer, err)
+	}
 
-			err = ftpfs.UploadAction(ftpConn, cfg.fs, kitPagesFolder, assetsFilesList, false, isDryRun).Run()
-			utils.ExitIfError(err)
+	// the deploy is completed, nothing to resume
+	if !isDryRun {
+		if err := journal.Remove(); err != nil {
+			return err
+		}
+	}
 
-			// prevent the remote FTP server to close the idle connection
-			err = noOpAction.Run()
-			utils.ExitIfError(err)
+	// compare the remote folder with the local build
+	if isVerify && !isDryRun {
+		if err := verifyDeploy(remoteConn, deployFiles, report); err != nil {
+			return err
 		}
+	}
 
-		// close the connection
-		err = ftpfs.LogoutAction(ftpConn).Run()
-		utils.ExitIfError(err)
+	// the lock must be released before closing the connection
+	lock.release()
 
-		cfg.log.Success("Done\n")
+	// close the connection
+	isDisconnected = true
+	if err := ftpfs.LogoutAction(remoteConn).Run(); err != nil {
+		return withExitCode(ExitCodeConnection, err)
 	}
+
+	cfg.log.Success("Done\n")
+	return nil
 }
 
 func deployCmdFlags(cmd *cobra.Command) {
-	cmd.Flags().BoolVarP(&isBackup, "backup", "b", true, "create a tar archive for the existing content on the remote FTP server")
+	cmd.Flags().BoolVarP(&isBackup, "backup", "b", true, "create a tar archive for the existing content on the remote server, rotated as set by deploy.backups in sveltin.json")
 	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
-	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "list of files to not be deleted from the FTP server. Default: .htaccess")
-	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file containing the list of files to not be deleted from the FTP server")
+	cmd.Flags().StringVar(&withEnv, "env", DefaultDeployEnv, "name of the deploy environment, read from .env.<name> and deploy.environments.<name> in sveltin.json")
+	cmd.Flags().BoolVar(&isListEnvs, "list-envs", false, "list the configured deploy environments")
+	cmd.Flags().BoolVar(&isFullDeploy, "full", false, "delete and upload everything, ignoring the manifest of the previous deploy")
+	cmd.Flags().BoolVar(&isAtomic, "atomic", false, "upload everything to a sibling folder (<folder>.sveltin-next) and swap it with the live one once done, keeping the previous content as <folder>.sveltin-prev")
+	cmd.Flags().BoolVar(&isResume, "resume", false, "resume the interrupted deploy, skipping the files already uploaded as recorded by .sveltin/deploy.journal")
+	cmd.Flags().StringVar(&deployTarget, "target", DeployTargetServer, "where to deploy: the remote server of the environment (server) or a branch of a git repository (git)")
+	cmd.Flags().BoolVar(&isVerify, "verify", false, "compare the remote folder with the local build once deployed (sizes, and checksums when the FTP server supports HASH, XCRC or MD5), exits with code 6 on any difference")
+	cmd.Flags().IntVarP(&uploadConcurrency, "concurrency", "c", 1, "number of connections used to upload files in parallel")
+	precompressCmdFlags(cmd)
+	cmd.Flags().BoolVar(&isMaintenance, "maintenance", false, "show a maintenance page while a full deploy replaces the remote folder content (default page: .sveltin/maintenance.html if it exists)")
+	cmd.Flags().BoolVar(&isMaintenanceHtaccess, "maintenance-htaccess", false, "also replace the .htaccess file to answer 503 with the maintenance page (implies --maintenance)")
+	cmd.Flags().StringArrayVar(&withChmod, "chmod", []string{}, "permissions for the uploaded paths matching a pattern, as <pattern>=<mode> (e.g. '*=0644', '*/=0755'), the last matching rule wins")
+	cmd.Flags().BoolVar(&isForceUnlock, "force-unlock", false, "replace the lock (.sveltin-deploy.lock) held on the remote folder by another deploy, locks older than an hour are replaced anyway")
+	cmd.Flags().BoolVarP(&isAutoConfirm, "yes", "y", false, "do not ask for confirmation, required when not running in a terminal")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", OutputText, "output format (text|json), json prints a report of the uploaded, deleted and skipped files to stdout and the logs to stderr")
+	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "list of files to not be deleted from the remote server. Default: .htaccess")
+	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file containing the list of files to not be deleted from the remote server")
 }
 
 func init() {
@@ -190,15 +346,565 @@ func init() {
 
 //=============================================================================
 
+// validateDeployFlags checks the flags shared by the deploy commands.
+// If --withExcludeFile is set, combines its lines with values from the --exclude flag.
+// Loads the rules from the .sveltinignore file, if any.
+func validateDeployFlags() error {
+	var err error
+	if len(withExcludeFile) != 0 {
+		lines, err := common.ReadFileLineByLine(cfg.fs, withExcludeFile)
+		if err != nil {
+			return err
+		}
+		withExclude = common.Union(withExclude, lines)
+	}
+
+	if ignoreRules, err = ftpfs.LoadIgnoreFile(cfg.fs, filepath.Join(cfg.pathMaker.GetRootFolder(), ftpfs.IgnoreFilename)); err != nil {
+		return err
+	}
+
+	if uploadConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be greater than 0, got %d", uploadConcurrency)
+	}
+	if isResume && isAtomic {
+		return errors.New("--resume cannot be used with --atomic, an interrupted atomic deploy never changes the live folder")
+	}
+	switch deployTarget {
+	case DeployTargetServer:
+	case DeployTargetGit:
+		if isAtomic || isResume || isVerify {
+			return errors.New("--atomic, --resume and --verify cannot be used with --target git")
+		}
+	default:
+		return fmt.Errorf("--target must be one of %s, %s, got '%s'", DeployTargetServer, DeployTargetGit, deployTarget)
+	}
+	return nil
+}
+
+// keepRules returns the rules for the paths not to be deleted from the remote folder:
+// the ones from the .sveltinignore file and the exact names set by --exclude and --withExcludeFile.
+func keepRules() *ftpfs.IgnoreRules {
+	return ignoreRules.Merge(ftpfs.ExcludeRules(withExclude))
+}
+
+// showIgnoredFiles shows the number of local files not uploaded, and the rule matching each one on dry run.
+func showIgnoredFiles(ignored map[string]*ftpfs.IgnoreRule) {
+	if len(ignored) == 0 {
+		return
+	}
+	if !isDryRun {
+		cfg.log.Infof("%d files not uploaded as set in %s", len(ignored), ftpfs.IgnoreFilename)
+		return
+	}
+	files := make([]string, 0, len(ignored))
+	for file := range ignored {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		cfg.log.Infof("Ignoring '%s' (%s)", file, ignored[file])
+	}
+}
+
+// verifyDeploy compares the remote folder with the local build and fails when they do not match.
+func verifyDeploy(conn ftpfs.RemoteServer, df *deployFiles, report *deployReport) error {
+	cfg.log.Info("Verifying the remote folder")
+	var result ftpfs.VerifyResult
+	if err := ftpfs.VerifyAction(conn, cfg.fs, df.toMap(), keepRules(), &result).Run(); err != nil {
+		return withExitCode(ExitCodeTransfer, err)
+	}
+	report.Verify = newDeployVerify(&result)
+	if outputFormat == OutputText {
+		feedbacks.ShowVerifyResult("Verify summary", &result)
+	}
+	if !result.IsValid() {
+		return withExitCode(ExitCodeVerify, fmt.Errorf("the remote folder does not match the local build: %d missing, %d extra, %d mismatched files",
+			len(result.Missing), len(result.Extra), len(result.Mismatched)))
+	}
+	return nil
+}
+
+// uploadedSince returns the files recorded by the journal and not part of the confirmed ones.
+func uploadedSince(journal *ftpfs.Journal, confirmed ftpfs.Manifest) []string {
+	files := []string{}
+	for file, sum := range journal.Confirmed() {
+		if confirmed[file] != sum {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// loadDeployJournal reads the journal of the interrupted deploy for the environment.
+func loadDeployJournal(path, env string, manifest ftpfs.Manifest) (*ftpfs.Journal, error) {
+	if exists, _ := afero.Exists(cfg.fs, path); !exists {
+		return nil, errors.New("no interrupted deploy to resume")
+	}
+	journal, err := ftpfs.LoadJournal(cfg.fs, path, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if journal.Environment() != env {
+		return nil, fmt.Errorf("the interrupted deploy was for the '%s' environment, not '%s'", journal.Environment(), env)
+	}
+	return journal, nil
+}
+
+// showDeployEnvs prints the configured deploy environments.
+func showDeployEnvs() {
+	names := getDeployEnvNames(cfg.fs)
+	envs := make(map[string]tpltypes.EnvProductionData, len(names))
+	for _, name := range names {
+		if env, err := loadDeployEnv(name); err == nil {
+			envs[name] = env.data
+		}
+	}
+	feedbacks.ShowDeployEnvironments(names, envs)
+}
+
+// newRemoteServer returns the RemoteServer implementation matching the DEPLOY_PROTOCOL value
+// of the environment. The FTP password is resolved first, see resolveFTPPassword.
+func newRemoteServer(env *deployEnv, isPlain bool) (ftpfs.RemoteServer, error) {
+	data := &env.data
+	switch strings.ToLower(data.DeployProtocol) {
+	case "", ftpfs.ProtocolFTP:
+		if err := resolveFTPPassword(env, true); err != nil {
+			return nil, err
+		}
+		conn := ftpfs.NewFTPServerConnection(newFTPConnectionConfig(*data))
+		conn.SetRootFolder(data.FTPServerFolder)
+		conn.SetLogger(cfg.log)
+		conn.SetConcurrency(uploadConcurrency)
+		conn.SetPlainOutput(isPlain)
+		return conn, nil
+	case ftpfs.ProtocolSFTP:
+		// no prompt when authenticating with a private key
+		if err := resolveFTPPassword(env, data.SSHKeyPath == ""); err != nil {
+			return nil, err
+		}
+		conn := ftpfs.NewSFTPServerConnection(newSFTPConnectionConfig(*data))
+		conn.SetRootFolder(data.FTPServerFolder)
+		conn.SetLogger(cfg.log)
+		conn.SetConcurrency(uploadConcurrency)
+		conn.SetPlainOutput(isPlain)
+		return conn, nil
+	case ftpfs.ProtocolS3:
+		conn := ftpfs.NewS3ServerConnection(newS3ConnectionConfig(*data))
+		conn.SetRootFolder(data.S3Prefix)
+		conn.SetLogger(cfg.log)
+		conn.SetConcurrency(uploadConcurrency)
+		conn.SetPlainOutput(isPlain)
+		return conn, nil
+	default:
+		return nil, sveltinerr.NewOptionNotValidError(data.DeployProtocol, ftpfs.SupportedProtocols())
+	}
+}
+
+// connectRemoteServer dials and logs in to the remote server.
+// Errors are wrapped with the exit code for connection and authentication failures.
+func connectRemoteServer(conn ftpfs.RemoteServer) error {
+	if err := ftpfs.DialAction(conn).Run(); err != nil {
+		// over SFTP, the authentication is part of the SSH handshake
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return withExitCode(ExitCodeAuth, err)
+		}
+		return withExitCode(ExitCodeConnection, err)
+	}
+	if err := ftpfs.LoginAction(conn).Run(); err != nil {
+		// over S3, the first request is sent on login
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return withExitCode(ExitCodeConnection, err)
+		}
+		return withExitCode(ExitCodeAuth, err)
+	}
+	// prevent the remote server to close the idle connection
+	return withExitCode(ExitCodeConnection, ftpfs.IdleAction(conn).Run())
+}
+
+// disconnectRemoteServer closes the connection unless already done. It is deferred
+// right after connecting, so that the error paths do not leave the connection open.
+func disconnectRemoteServer(conn ftpfs.RemoteServer, isDisconnected *bool) {
+	if *isDisconnected {
+		return
+	}
+	*isDisconnected = true
+	_ = ftpfs.LogoutAction(conn).Run()
+}
+
+// confirmDeploy asks for confirmation, unless --yes is set.
+func confirmDeploy() (bool, error) {
+	if isAutoConfirm {
+		return true, nil
+	}
+	return confirm.Run(&confirm.Config{Question: "Continue?"})
+}
+
 func newFTPConnectionConfig(data tpltypes.EnvProductionData) *ftpfs.FTPConnectionConfig {
 	return &ftpfs.FTPConnectionConfig{
-		Host:     data.FTPHost,
-		Port:     data.FTPPort,
-		User:     data.FTPUser,
-		Password: data.FTPPassword,
-		Timeout:  data.FTPDialTimeout,
-		IsEPSV:   data.FTPEPSVMode,
+		Host:                  data.FTPHost,
+		Port:                  data.FTPPort,
+		User:                  data.FTPUser,
+		Password:              data.FTPPassword,
+		Timeout:               data.FTPDialTimeout,
+		IsEPSV:                data.FTPEPSVMode,
+		TLSMode:               data.FTPTLS,
+		TLSInsecureSkipVerify: data.FTPTLSInsecureSkipVerify,
+		TLSCAFile:             data.FTPTLSCAFile,
+	}
+}
+
+func newSFTPConnectionConfig(data tpltypes.EnvProductionData) *ftpfs.SFTPConnectionConfig {
+	return &ftpfs.SFTPConnectionConfig{
+		Host:                  data.FTPHost,
+		Port:                  data.FTPPort,
+		User:                  data.FTPUser,
+		Password:              data.FTPPassword,
+		Timeout:               data.FTPDialTimeout,
+		KeyPath:               data.SSHKeyPath,
+		KeyPassphrase:         data.SSHKeyPassphrase,
+		KnownHostsPath:        data.SSHKnownHostsPath,
+		InsecureIgnoreHostKey: data.SSHInsecureIgnoreHostKey,
+	}
+}
+
+// newS3ConnectionConfig falls back to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables when the credentials are not set for the environment.
+func newS3ConnectionConfig(data tpltypes.EnvProductionData) *ftpfs.S3ConnectionConfig {
+	config := &ftpfs.S3ConnectionConfig{
+		Bucket:          data.S3Bucket,
+		Region:          data.S3Region,
+		Endpoint:        data.S3Endpoint,
+		AccessKeyID:     data.S3AccessKeyID,
+		SecretAccessKey: data.S3SecretAccessKey,
+		SessionToken:    data.S3SessionToken,
+		Timeout:         data.FTPDialTimeout,
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if config.SecretAccessKey == "" {
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if config.SessionToken == "" {
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return config
+}
+
+// deployFiles is the struct representing the local files to be deployed.
+// Files from the "kit.adapter.pages" folder are uploaded without the parent folder name,
+// files from the "kit.adapter.assets" folder (when different) are uploaded as whole folder.
+type deployFiles struct {
+	pagesFolder  string
+	assetsFolder string
+	pagesDirs    []string
+	pagesFiles   []string
+	assetsDirs   []string
+	assetsFiles  []string
+	// ignored is the map of the files not to be uploaded, keyed by their path on the remote folder
+	ignored map[string]*ftpfs.IgnoreRule
+}
+
+func newDeployFiles(adapter tpltypes.SvelteKitAdapterData, ignore *ftpfs.IgnoreRules) (*deployFiles, error) {
+	var err error
+	df := &deployFiles{
+		pagesFolder:  adapter.Pages,
+		assetsFolder: adapter.Assets,
+		ignored:      map[string]*ftpfs.IgnoreRule{},
+	}
+
+	if df.pagesDirs, err = walkLocal(cfg.fs, EntryTypeFolder, df.pagesFolder, true); err != nil {
+		return nil, err
+	}
+	if df.pagesFiles, err = walkLocal(cfg.fs, EntryTypeFile, df.pagesFolder, true); err != nil {
+		return nil, err
+	}
+
+	/**
+	* Check if pages and assets props for adapter-static are differents.
+	* If true, upload the entire kit.adapter.assets folder.
+	**/
+	if df.pagesFolder != df.assetsFolder {
+		if df.assetsDirs, err = walkLocal(cfg.fs, EntryTypeFolder, df.assetsFolder, false); err != nil {
+			return nil, err
+		}
+		if df.assetsFiles, err = walkLocal(cfg.fs, EntryTypeFile, df.assetsFolder, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if !ignore.IsEmpty() {
+		df.pagesDirs = df.filterDirs(ignore, df.pagesDirs)
+		df.pagesFiles = df.filterFiles(ignore, df.pagesFiles, df.toRemotePath)
+		df.assetsDirs = df.filterDirs(ignore, df.assetsDirs)
+		df.assetsFiles = df.filterFiles(ignore, df.assetsFiles, filepath.ToSlash)
+	}
+	return df, nil
+}
+
+// filterDirs returns the folders, as paths on the remote folder, not matching the ignore rules.
+func (df *deployFiles) filterDirs(ignore *ftpfs.IgnoreRules, dirs []string) []string {
+	filtered := []string{}
+	for _, dir := range dirs {
+		if ignore.Match(dir, true) == nil {
+			filtered = append(filtered, dir)
+		}
+	}
+	return filtered
+}
+
+// filterFiles returns the local files whose path on the remote folder does not match the ignore rules.
+func (df *deployFiles) filterFiles(ignore *ftpfs.IgnoreRules, files []string, toRemotePath func(string) string) []string {
+	filtered := []string{}
+	for _, file := range files {
+		remotePath := toRemotePath(file)
+		if rule := ignore.Match(remotePath, false); rule != nil {
+			df.ignored[remotePath] = rule
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
+// toRemotePath returns the path on the remote folder for a local pages file.
+func (df *deployFiles) toRemotePath(file string) string {
+	return filepath.ToSlash(utils.ToBasePath(file, df.pagesFolder))
+}
+
+// toMap returns the map of the files to be deployed, keyed by their path on the remote folder.
+func (df *deployFiles) toMap() map[string]string {
+	files := make(map[string]string, len(df.pagesFiles)+len(df.assetsFiles))
+	for _, file := range df.pagesFiles {
+		files[df.toRemotePath(file)] = file
+	}
+	for _, file := range df.assetsFiles {
+		files[filepath.ToSlash(file)] = file
+	}
+	return files
+}
+
+// remotePaths returns the list of the files to be deployed as paths on the remote folder.
+func (df *deployFiles) remotePaths() []string {
+	files := []string{}
+	for file := range df.toMap() {
+		files = append(files, file)
+	}
+	return files
+}
+
+// deployAll deletes the remote folder content and uploads all the local files.
+// When resuming, only the files not uploaded yet are, without deleting anything.
+// With a maintenance page, it is uploaded right after the deletion and the index.html
+// and .htaccess files from the build replace it as the last step.
+func deployAll(conn ftpfs.RemoteServer, df *deployFiles, journal *ftpfs.Journal, maintenance *maintenancePage, report *deployReport) error {
+	if isResume {
+		pending := []string{}
+		for file := range df.toMap() {
+			if journal.IsConfirmed(file) {
+				report.Skipped = append(report.Skipped, file)
+			} else {
+				pending = append(pending, file)
+			}
+		}
+		if err := uploadPending(conn, df, pending, journal.Confirmed(), maintenance, report); err != nil {
+			return err
+		}
+		if maintenance != nil {
+			return maintenance.end(conn, df)
+		}
+		return nil
+	}
+
+	// delete content from the remote folder with exclude list
+	cfg.log.Important(fmt.Sprintf("If present, the following files will not be deleted from the remote folder: %s", strings.Join(withExclude, ", ")))
+	if err := ftpfs.DeleteAllAction(conn, keepRules(), isDryRun).Run(); err != nil {
+		return err
+	}
+
+	pagesFiles, lastFiles := df.pagesFiles, []string{}
+	if maintenance != nil {
+		if err := maintenance.start(conn); err != nil {
+			return err
+		}
+		pagesFiles, lastFiles = maintenance.splitLast(df, df.pagesFiles)
+	}
+
+	if err := uploadFolder(conn, df.pagesFolder, df.pagesDirs, pagesFiles, true); err != nil {
+		return err
+	}
+	if err := uploadFolder(conn, df.assetsFolder, df.assetsDirs, df.assetsFiles, false); err != nil {
+		return err
+	}
+	if maintenance != nil {
+		if err := uploadFolder(conn, df.pagesFolder, nil, lastFiles, true); err != nil {
+			return err
+		}
+		if err := maintenance.end(conn, df); err != nil {
+			return err
+		}
+	}
+	report.Uploaded = df.remotePaths()
+	return nil
+}
+
+// deployAtomic uploads all the local files and the manifest to a sibling folder and swaps it
+// with the live one once done. The sibling folder is removed when something goes wrong.
+func deployAtomic(conn ftpfs.RemoteServer, df *deployFiles, manifest ftpfs.Manifest, report *deployReport) error {
+	liveFolder := conn.RootFolder()
+	cfg.log.Infof("Preparing the staging folder '%s'", ftpfs.NextFolder(liveFolder))
+
+	err := ftpfs.PrepareAtomicAction(conn, keepRules(), isDryRun).Run()
+	if err == nil {
+		err = uploadFolder(conn, df.pagesFolder, df.pagesDirs, df.pagesFiles, true)
+	}
+	if err == nil {
+		err = uploadFolder(conn, df.assetsFolder, df.assetsDirs, df.assetsFiles, false)
+	}
+	if err == nil {
+		err = ftpfs.WriteManifestAction(conn, manifest, isDryRun).Run()
+	}
+	if err == nil {
+		cfg.log.Infof("Swapping the staging folder with '%s'", liveFolder)
+		err = ftpfs.SwapFoldersAction(conn, liveFolder, isDryRun).Run()
+	}
+
+	if err != nil {
+		cfg.log.Important("Deploy failed! Removing the staging folder, the live folder has not been changed")
+		if cleanupErr := ftpfs.CleanupAtomicAction(conn, liveFolder, isDryRun).Run(); cleanupErr != nil {
+			cfg.log.Errorf("Could not remove the staging folder: %s", cleanupErr.Error())
+		}
+		return err
+	}
+	report.Uploaded = df.remotePaths()
+	return nil
+}
+
+// deployChanges uploads new and changed files and deletes the ones no longer existing locally.
+func deployChanges(conn ftpfs.RemoteServer, df *deployFiles, diff *ftpfs.ManifestDiff, localManifest, remoteManifest ftpfs.Manifest, journal *ftpfs.Journal, report *deployReport) error {
+	toUpload := make(map[string]bool, len(diff.Added)+len(diff.Changed))
+	for _, file := range common.Union(diff.Added, diff.Changed) {
+		toUpload[file] = true
+	}
+	pending := []string{}
+	for file := range localManifest {
+		if toUpload[file] && !journal.IsConfirmed(file) {
+			pending = append(pending, file)
+		} else {
+			report.Skipped = append(report.Skipped, file)
+		}
+	}
+
+	if diff.IsEmpty() {
+		cfg.log.Info("The remote folder is already up to date")
+		return nil
+	}
+
+	if err := uploadPending(conn, df, pending, remoteManifest, nil, report); err != nil {
+		return err
+	}
+
+	// never delete files matching the keep rules
+	keep := keepRules()
+	toDelete := []string{}
+	for _, file := range diff.Removed {
+		if rule := keep.Match(file, false); rule != nil {
+			if isDryRun {
+				cfg.log.Infof("Keeping '%s' (%s)", file, rule)
+			}
+			report.Skipped = append(report.Skipped, file)
+		} else {
+			toDelete = append(toDelete, file)
+		}
+	}
+	if len(toDelete) > 0 {
+		cfg.log.Important("Deleting the files no longer existing from the remote folder")
+		if err := ftpfs.DeleteFilesAction(conn, toDelete, isDryRun).Run(); err != nil {
+			return err
+		}
+		report.Deleted = toDelete
+	}
+	return nil
+}
+
+// uploadPending creates the missing remote folders and uploads the local files
+// matching the list of paths on the remote folder. With a maintenance page, the
+// files replacing it are uploaded last.
+func uploadPending(conn ftpfs.RemoteServer, df *deployFiles, pending []string, existing ftpfs.Manifest, maintenance *maintenancePage, report *deployReport) error {
+	toUpload := make(map[string]bool, len(pending))
+	for _, file := range pending {
+		toUpload[file] = true
+	}
+	pagesFiles := []string{}
+	for _, file := range df.pagesFiles {
+		if toUpload[df.toRemotePath(file)] {
+			pagesFiles = append(pagesFiles, file)
+		}
+	}
+	assetsFiles := []string{}
+	for _, file := range df.assetsFiles {
+		if toUpload[filepath.ToSlash(file)] {
+			assetsFiles = append(assetsFiles, file)
+		}
+	}
+
+	dirs := existing.MissingDirs(pending)
+	if len(dirs) > 0 {
+		cfg.log.Info("Creating the missing remote folders")
+		if err := ftpfs.MakeDirsAction(conn, dirs, isDryRun).Run(); err != nil {
+			return err
+		}
+		applyChmodRules(conn, dirs, nil)
+	}
+
+	// the files replacing the maintenance page are held back until the rest is uploaded
+	lastFiles := []string{}
+	if maintenance != nil {
+		pagesFiles, lastFiles = maintenance.splitLast(df, pagesFiles)
+	}
+
+	if err := uploadFolder(conn, df.pagesFolder, nil, pagesFiles, true); err != nil {
+		return err
+	}
+	if err := uploadFolder(conn, df.assetsFolder, nil, assetsFiles, false); err != nil {
+		return err
+	}
+	if err := uploadFolder(conn, df.pagesFolder, nil, lastFiles, true); err != nil {
+		return err
+	}
+	report.Uploaded = pending
+	return nil
+}
+
+// uploadFolder creates the folders structure and uploads the files for a local folder.
+func uploadFolder(conn ftpfs.RemoteServer, folder string, dirs, files []string, replaceBasePath bool) error {
+	noOpAction := ftpfs.IdleAction(conn)
+
+	if len(dirs) > 0 {
+		cfg.log.Infof("Creating remote folders structure for '%s'", folder)
+		if err := ftpfs.MakeDirsAction(conn, dirs, isDryRun).Run(); err != nil {
+			return err
+		}
+		applyChmodRules(conn, dirs, nil)
+		// prevent the remote server to close the idle connection
+		if err := noOpAction.Run(); err != nil {
+			return err
+		}
+	}
+
+	if len(files) > 0 {
+		cfg.log.Infof("Uploading files to the remote folder '%s'", folder)
+		if err := ftpfs.UploadAction(conn, cfg.fs, folder, files, replaceBasePath, isDryRun).Run(); err != nil {
+			return err
+		}
+		applyChmodRules(conn, nil, toRemotePaths(folder, files, replaceBasePath))
+		// prevent the remote server to close the idle connection
+		if err := noOpAction.Run(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func walkLocal(fs afero.Fs, fType EntryType, dirname string, replaceBasePath bool) ([]string, error) {
@@ -0,0 +1,298 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/prompti/confirm"
+	"github.com/sveltinio/sveltin/helpers"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/tui/activehelps"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/tui/prompts"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+//=============================================================================
+
+var (
+	withKeepBackups int
+	withMaxAgeDays  int
+	withMaxSize     string
+)
+
+var deployBackupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "Manage the backup archives created by the deploy command",
+	Long: `Command used to list, inspect and prune the backup archives stored within the backups folder.
+The archives are stored per deploy environment (backups/<project>/<env>), use --env to select it.
+The archives created by older releases directly within the backups folder belong to the default
+environment (production).
+
+Retention policies can be set in the deploy.backups section of sveltin.json and are applied
+after each deploy creating a backup:
+
+  "deploy": {
+    "backups": {
+      "keep": 10,
+      "maxAgeDays": 30,
+      "maxSize": "500MB"
+    }
+  }
+
+keep is the number of the most recent backups to keep, maxAgeDays prunes the backups older than
+the number of days and maxSize prunes the oldest backups exceeding the total size.
+The most recent backup is never pruned.
+
+Run 'sveltin deploy backups -h' for further details.
+`,
+	ValidArgs:             []string{"list", "inspect", "prune"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+}
+
+var deployBackupsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the backup archives",
+	Long: `Command used to list the backup archives of the deploy environment, newest first.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   DeployBackupsListCmdRun,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument."))
+		return comps, cobra.ShellCompDirectiveDefault
+	},
+}
+
+var deployBackupsInspectCmd = &cobra.Command{
+	Use:   "inspect [name]",
+	Short: "List the files within a backup archive",
+	Long: `Command used to list the files within one of the backup archives.
+
+Without the name argument, it prompts to select the backup archive from the available ones, newest first.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.MaximumNArgs(1),
+	Run:                   DeployBackupsInspectCmdRun,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		backups, _ := getEnvBackups(withEnv)
+		names := []string{}
+		for _, b := range backups {
+			names = append(names, b.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var deployBackupsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the backup archives exceeding the retention policies",
+	Long: `Command used to delete the backup archives exceeding the retention policies.
+
+The policies are read from the deploy.backups section of sveltin.json, the --keep, --days and
+--max-size flags override them. The most recent backup is never pruned.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   DeployBackupsPruneCmdRun,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument but accepts flags."))
+		return comps, cobra.ShellCompDirectiveDefault
+	},
+}
+
+// DeployBackupsListCmdRun is the actual work function.
+func DeployBackupsListCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	backups, err := getEnvBackups(withEnv)
+	utils.ExitIfError(err)
+
+	feedbacks.ShowBackups("Backup archives", backups)
+}
+
+// DeployBackupsInspectCmdRun is the actual work function.
+func DeployBackupsInspectCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	backups, err := getEnvBackups(withEnv)
+	utils.ExitIfError(err)
+
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+	backup, err := prompts.SelectBackupHandler(backups, name)
+	utils.ExitIfError(err)
+
+	entries, err := helpers.GetBackupEntries(cfg.fs, backup.Path)
+	utils.ExitIfError(err)
+
+	feedbacks.ShowBackupEntries(backup.Name, entries)
+}
+
+// DeployBackupsPruneCmdRun is the actual work function.
+func DeployBackupsPruneCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Prune the backup archives"))
+
+	settings := cfg.projectSettings.Deploy.Backups
+	if cmd.Flags().Changed("keep") {
+		settings.Keep = withKeepBackups
+	}
+	if cmd.Flags().Changed("days") {
+		settings.MaxAgeDays = withMaxAgeDays
+	}
+	if cmd.Flags().Changed("max-size") {
+		settings.MaxSize = withMaxSize
+	}
+	retention, err := backupRetention(settings)
+	utils.ExitIfError(err)
+	if retention.IsEmpty() {
+		utils.ExitIfError(errors.New("no retention policies, set them in the deploy.backups section of sveltin.json or use the --keep, --days and --max-size flags"))
+	}
+
+	backups, err := getEnvBackups(withEnv)
+	utils.ExitIfError(err)
+	toPrune := helpers.BackupsToPrune(backups, retention, time.Now())
+	if len(toPrune) == 0 {
+		cfg.log.Info("Nothing to prune")
+		return
+	}
+
+	feedbacks.ShowBackups("The following backup archives will be deleted", toPrune)
+	if isDryRun {
+		feedbacks.ShowDryRunMessage()
+	}
+
+	if !isAutoConfirm {
+		isConfirm, err := confirm.Run(&confirm.Config{Question: "Continue?"})
+		utils.ExitIfError(err)
+		if !isConfirm {
+			return
+		}
+	}
+
+	utils.ExitIfError(deleteBackups(toPrune, isDryRun))
+	cfg.log.Success("Done\n")
+}
+
+func deployBackupsPruneCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&withKeepBackups, "keep", 0, "number of the most recent backups to keep")
+	cmd.Flags().IntVar(&withMaxAgeDays, "days", 0, "delete the backups older than the number of days")
+	cmd.Flags().StringVar(&withMaxSize, "max-size", "", "maximum total size of the backups (e.g. 500MB)")
+	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
+	cmd.Flags().BoolVarP(&isAutoConfirm, "yes", "y", false, "do not ask for confirmation")
+}
+
+func init() {
+	deployBackupsCmd.PersistentFlags().StringVar(&withEnv, "env", DefaultDeployEnv, "name of the deploy environment")
+	deployBackupsPruneCmdFlags(deployBackupsPruneCmd)
+	deployBackupsCmd.AddCommand(deployBackupsListCmd)
+	deployBackupsCmd.AddCommand(deployBackupsInspectCmd)
+	deployBackupsCmd.AddCommand(deployBackupsPruneCmd)
+	deployCmd.AddCommand(deployBackupsCmd)
+}
+
+//=============================================================================
+
+// backupsFolderPath returns the path to the folder storing the backup archives of the deploy environment.
+func backupsFolderPath(envName string) (string, error) {
+	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+	projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfg.pathMaker.GetRootFolder(), BackupsFolder, projectName, strings.ToLower(envName)), nil
+}
+
+// getEnvBackups returns the backup archives of the deploy environment, newest first.
+// For the default environment, the archives created by older releases directly within
+// the backups folder are returned as well.
+func getEnvBackups(envName string) ([]helpers.BackupFile, error) {
+	backupsPath, err := backupsFolderPath(envName)
+	if err != nil {
+		return nil, err
+	}
+	backups, err := helpers.GetAllBackups(cfg.fs, backupsPath)
+	if err != nil || !strings.EqualFold(envName, DefaultDeployEnv) {
+		return backups, err
+	}
+
+	legacyBackups, err := helpers.GetAllBackups(cfg.fs, filepath.Join(cfg.pathMaker.GetRootFolder(), BackupsFolder))
+	if err != nil {
+		return nil, err
+	}
+	backups = append(backups, legacyBackups...)
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].ModTime.After(backups[j].ModTime)
+	})
+	return backups, nil
+}
+
+// backupRetention returns the retention policies from the deploy.backups settings.
+func backupRetention(settings tpltypes.DeployBackupsData) (helpers.BackupRetention, error) {
+	retention := helpers.BackupRetention{
+		Keep:   settings.Keep,
+		MaxAge: time.Duration(settings.MaxAgeDays) * 24 * time.Hour,
+	}
+	if settings.Keep < 0 || settings.MaxAgeDays < 0 {
+		return retention, errors.New("the backups retention values cannot be negative")
+	}
+	if settings.MaxSize != "" {
+		size, err := utils.ParseHumanBytes(settings.MaxSize)
+		if err != nil {
+			return retention, err
+		}
+		retention.MaxSize = size
+	}
+	return retention, nil
+}
+
+// pruneBackups deletes the backup archives of the deploy environment exceeding the retention policies.
+func pruneBackups(envName string, retention helpers.BackupRetention, dryRun bool) error {
+	if retention.IsEmpty() {
+		return nil
+	}
+	backups, err := getEnvBackups(envName)
+	if err != nil {
+		return err
+	}
+	return deleteBackups(helpers.BackupsToPrune(backups, retention, time.Now()), dryRun)
+}
+
+func deleteBackups(backups []helpers.BackupFile, dryRun bool) error {
+	for _, b := range backups {
+		cfg.log.Infof("Deleting the backup archive '%s' (%s)", b.Name, utils.ToHumanBytes(b.Size))
+		if dryRun {
+			continue
+		}
+		if err := cfg.fs.Remove(b.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	withChmod  []string
+	chmodRules *ftpfs.ChmodRules
+	// isChmodUnsupported is set once the remote server rejected the chmod command, not to try again.
+	isChmodUnsupported bool
+)
+
+// loadChmodRules parses the chmod rules from the deploy.chmod section in sveltin.json
+// followed by the --chmod flag values, which take precedence.
+func loadChmodRules() error {
+	settingsRules, err := ftpfs.ParseChmodRules("sveltin.json", cfg.projectSettings.Deploy.Chmod)
+	if err != nil {
+		return err
+	}
+	flagRules, err := ftpfs.ParseChmodRules("--chmod", withChmod)
+	if err != nil {
+		return err
+	}
+	chmodRules = settingsRules.Merge(flagRules)
+	return nil
+}
+
+// applyChmodRules sets the permissions of the remote folders and files, relative to the remote folder,
+// matching the chmod rules. Servers not supporting it are warned about once, failures on single
+// paths are warnings too: the deploy goes on.
+func applyChmodRules(conn ftpfs.RemoteServer, dirs, files []string) {
+	if chmodRules.IsEmpty() || isChmodUnsupported {
+		return
+	}
+
+	var changes []ftpfs.ChmodChange
+	err := ftpfs.ChmodAction(conn, chmodRules, dirs, files, &changes, isDryRun).Run()
+	if errors.Is(err, ftpfs.ErrChmodNotSupported) {
+		isChmodUnsupported = true
+		cfg.log.Important("The remote server does not support changing the file permissions, the chmod rules are skipped")
+		return
+	}
+	if err != nil {
+		cfg.log.Importantf("Could not change the file permissions, got error '%s'", err.Error())
+		return
+	}
+
+	for _, change := range changes {
+		switch {
+		case isDryRun:
+			cfg.log.Infof("Setting %04o on '%s' (%s)", change.Mode, change.Path, change.Rule)
+		case change.Err != nil:
+			cfg.log.Importantf("Could not set %04o on '%s', got error '%s'", change.Mode, change.Path, change.Err.Error())
+		}
+	}
+}
+
+// toRemotePaths returns the paths on the remote folder for the local files within the folder.
+func toRemotePaths(folder string, files []string, replaceBasePath bool) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if replaceBasePath {
+			file = utils.ToBasePath(file, folder)
+		}
+		paths = append(paths, filepath.ToSlash(file))
+	}
+	return paths
+}
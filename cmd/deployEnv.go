@@ -0,0 +1,177 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/common"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+)
+
+// DefaultDeployEnv is the name of the deploy environment used when --env is not set.
+const DefaultDeployEnv string = "production"
+
+// dotEnvPrefix is the prefix for the dotenv file of a named deploy environment (.env.<name>).
+const dotEnvPrefix string = ".env."
+
+// deployEnv is the struct representing a resolved deploy environment.
+type deployEnv struct {
+	name    string
+	envFile string
+	data    tpltypes.EnvProductionData
+	exclude []string
+	backup  *bool
+}
+
+// loadDeployEnv resolves the deploy environment by name. Values are read, in order, from:
+//   - the file set by "envFile" for the environment in sveltin.json;
+//   - the .env.<name> file;
+//   - the .env.production file, for the default environment or when the environment is only defined in sveltin.json.
+//
+// Not empty values from the "deploy.environments.<name>" section of sveltin.json override them.
+func loadDeployEnv(name string) (*deployEnv, error) {
+	name = strings.ToLower(name)
+	settings, inSettings := cfg.projectSettings.Deploy.Environments[name]
+
+	env := &deployEnv{name: name}
+	switch {
+	case inSettings && settings.EnvFile != "":
+		env.envFile = settings.EnvFile
+	case dotEnvExists(dotEnvPrefix + name):
+		env.envFile = dotEnvPrefix + name
+	case inSettings, name == DefaultDeployEnv:
+		env.envFile = DotEnvProdFile
+	default:
+		return nil, sveltinerr.NewOptionNotValidError(name, getDeployEnvNames(cfg.fs))
+	}
+
+	if env.envFile == DotEnvProdFile {
+		env.data = cfg.prodData
+	} else {
+		if !dotEnvExists(env.envFile) {
+			return nil, sveltinerr.NewFileNotFoundError(env.envFile)
+		}
+		data, err := loadEnvFile(env.envFile)
+		if err != nil {
+			return nil, err
+		}
+		env.data = data
+	}
+
+	if inSettings {
+		applyDeployEnvSettings(env, settings)
+	}
+	return env, nil
+}
+
+// applyDeployEnvSettings overrides the environment values with the not empty ones from sveltin.json.
+func applyDeployEnvSettings(env *deployEnv, settings tpltypes.DeployEnvironmentData) {
+	if settings.BaseURL != "" {
+		env.data.BaseURL = settings.BaseURL
+	}
+	if settings.Protocol != "" {
+		env.data.DeployProtocol = settings.Protocol
+	}
+	if settings.Host != "" {
+		env.data.FTPHost = settings.Host
+	}
+	if settings.Port != 0 {
+		env.data.FTPPort = settings.Port
+	}
+	if settings.User != "" {
+		env.data.FTPUser = settings.User
+	}
+	if settings.ServerFolder != "" {
+		env.data.FTPServerFolder = settings.ServerFolder
+	}
+	if settings.Bucket != "" {
+		env.data.S3Bucket = settings.Bucket
+	}
+	if settings.Region != "" {
+		env.data.S3Region = settings.Region
+	}
+	if settings.Endpoint != "" {
+		env.data.S3Endpoint = settings.Endpoint
+	}
+	if settings.Prefix != "" {
+		env.data.S3Prefix = settings.Prefix
+	}
+	if settings.GitRemote != "" {
+		env.data.GitRemote = settings.GitRemote
+	}
+	if settings.GitBranch != "" {
+		env.data.GitBranch = settings.GitBranch
+	}
+	env.exclude = settings.Exclude
+	env.backup = settings.Backup
+}
+
+// applyDeployEnvFlags merges the environment exclude list with the --exclude flag values and
+// uses the environment backup setting unless the --backup flag is explicitly set.
+func applyDeployEnvFlags(cmd *cobra.Command, env *deployEnv) {
+	withExclude = common.Union(withExclude, env.exclude)
+	if env.backup != nil && cmd.Flags().Lookup("backup") != nil && !cmd.Flags().Changed("backup") {
+		isBackup = *env.backup
+	}
+}
+
+// getDeployEnvNames returns the sorted list of the deploy environments defined either in
+// sveltin.json or by the .env.<name> files within the project root setting any deploy key
+// (DEPLOY_PROTOCOL, FTP_*, S3_*, SSH_* or GIT_*).
+// Other dotenv files (e.g. .env.development, .env.example) are not deploy environments.
+func getDeployEnvNames(fs afero.Fs) []string {
+	names := []string{}
+	for name := range cfg.projectSettings.Deploy.Environments {
+		names = append(names, name)
+	}
+
+	rootFolder := cfg.pathMaker.GetRootFolder()
+	if entries, err := afero.ReadDir(fs, rootFolder); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), dotEnvPrefix) {
+				continue
+			}
+			if hasDeployKeys(fs, filepath.Join(rootFolder, entry.Name())) {
+				names = common.Union(names, []string{strings.TrimPrefix(entry.Name(), dotEnvPrefix)})
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// deployKeyPrefixes are the prefixes of the dotenv keys configuring a deploy target.
+var deployKeyPrefixes = []string{"DEPLOY_PROTOCOL", "FTP_", "S3_", "SSH_", "GIT_"}
+
+// hasDeployKeys returns true if the dotenv file sets any key configuring a deploy target.
+func hasDeployKeys(fs afero.Fs, filename string) bool {
+	content, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		for _, prefix := range deployKeyPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func dotEnvExists(filename string) bool {
+	exists, _ := afero.Exists(cfg.fs, filename)
+	return exists
+}
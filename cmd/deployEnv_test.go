@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestHasDeployKeys(t *testing.T) {
+	is := is.New(t)
+
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		".env.production":  "FTP_HOST=example.com\nFTP_USER=me\n",
+		".env.bucket":      "DEPLOY_PROTOCOL=s3\nexport S3_BUCKET=www\nS3_REGION=eu-west-1\n",
+		".env.pages":       "GIT_REMOTE=git@github.com:me/site.git\nSSH_KEY_PATH=~/.ssh/id_ed25519\n",
+		".env.development": "VITE_API=http://localhost:3000\n",
+	}
+	for name, content := range files {
+		is.NoErr(afero.WriteFile(fs, name, []byte(content), 0644))
+	}
+
+	is.True(hasDeployKeys(fs, ".env.production"))
+	is.True(hasDeployKeys(fs, ".env.bucket"))
+	is.True(hasDeployKeys(fs, ".env.pages"))
+	is.True(!hasDeployKeys(fs, ".env.development"))
+	is.True(!hasDeployKeys(fs, ".env.missing"))
+}
@@ -0,0 +1,226 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/confirm"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/shell"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// Targets for the deploy command.
+const (
+	DeployTargetServer string = "server"
+	DeployTargetGit    string = "git"
+)
+
+// Defaults for the git deploy target.
+const (
+	DefaultGitRemote string = "origin"
+	DefaultGitBranch string = "gh-pages"
+)
+
+// noJekyllFile disables Jekyll on GitHub Pages, it would not serve the SvelteKit _app folder otherwise.
+const noJekyllFile string = ".nojekyll"
+
+// deployToGit commits the content of the "kit.adapter.pages" folder to the branch of the git remote
+// repository and pushes it. The branch content is replaced, except the paths matching the keep rules.
+func deployToGit(env *deployEnv, report *deployReport) error {
+	gitClient := shell.NewGitClient()
+	repoURL, err := gitDeployRemoteURL(gitClient, env.data.GitRemote)
+	if err != nil {
+		return err
+	}
+	branch := env.data.GitBranch
+	if branch == "" {
+		branch = DefaultGitBranch
+	}
+	report.Mode = DeployTargetGit
+	cfg.log.Infof("Publishing '%s' to the %s branch of %s", cfg.projectSettings.SvelteKit.Adapter.Pages, branch, repoURL)
+
+	deployFiles, err := newDeployFiles(cfg.projectSettings.SvelteKit.Adapter, ignoreRules)
+	if err != nil {
+		return err
+	}
+	showIgnoredFiles(deployFiles.ignored)
+	for file := range deployFiles.ignored {
+		report.Skipped = append(report.Skipped, file)
+	}
+
+	if outputFormat == OutputText && isDryRun {
+		feedbacks.ShowDryRunMessage()
+	}
+	if !isAutoConfirm {
+		isConfirm, err := confirm.Run(&confirm.Config{Question: "Continue?"})
+		if err != nil {
+			return err
+		}
+		if !isConfirm {
+			return nil
+		}
+	}
+
+	workDir, err := os.MkdirTemp("", "sveltin-deploy-git-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	branchExists, err := gitClient.RunBranchExists(repoURL, branch)
+	if err != nil {
+		return withExitCode(ExitCodeConnection, err)
+	}
+	if branchExists {
+		err = gitClient.RunCloneBranch(repoURL, branch, workDir)
+	} else {
+		cfg.log.Infof("The %s branch does not exist, it will be created", branch)
+		err = gitClient.RunInitBranch(repoURL, branch, workDir)
+	}
+	if err != nil {
+		return withExitCode(ExitCodeConnection, err)
+	}
+
+	if err := clearGitWorkTree(workDir, keepRules()); err != nil {
+		return err
+	}
+	if err := copyToGitWorkTree(workDir, deployFiles); err != nil {
+		return err
+	}
+
+	changes, err := gitClient.RunStageAll(workDir)
+	if err != nil {
+		return err
+	}
+	if changes.IsEmpty() {
+		cfg.log.Success("Nothing to deploy, the branch is up to date\n")
+		return nil
+	}
+	report.Uploaded = append(changes.Added, changes.Modified...)
+	report.Deleted = changes.Deleted
+	if outputFormat == OutputText {
+		feedbacks.ShowDeploySummary(changes.Added, changes.Modified, changes.Deleted)
+	}
+	if isDryRun {
+		return nil
+	}
+
+	message, err := gitDeployCommitMessage(env.name)
+	if err != nil {
+		return err
+	}
+	if err := gitClient.RunCommit(workDir, message); err != nil {
+		return err
+	}
+	if err := gitClient.RunPush(workDir, branch); err != nil {
+		return withExitCode(ExitCodeTransfer, err)
+	}
+
+	cfg.log.Success("Done\n")
+	return nil
+}
+
+// gitDeployRemoteURL returns the URL of the remote repository. The remote is either a URL,
+// a local path or the name of a remote of the project repository (default: origin).
+func gitDeployRemoteURL(gitClient *shell.GitShell, remote string) (string, error) {
+	if remote == "" {
+		remote = DefaultGitRemote
+	}
+	if strings.Contains(remote, "://") || strings.Contains(remote, "@") {
+		return remote, nil
+	}
+	// local paths are made absolute, git runs within the work tree
+	if exists, _ := afero.DirExists(cfg.fs, remote); exists {
+		return filepath.Abs(remote)
+	}
+	if strings.ContainsAny(remote, ":/\\") {
+		return remote, nil
+	}
+	return gitClient.RunRemoteGetURL(cfg.pathMaker.GetRootFolder(), remote)
+}
+
+// clearGitWorkTree deletes the files within the work tree except the .git folder and the paths matching the keep rules.
+func clearGitWorkTree(workDir string, keep *ftpfs.IgnoreRules) error {
+	files := []string{}
+	err := afero.Walk(cfg.fs, workDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(workDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case rel == ".git":
+			return filepath.SkipDir
+		case info.IsDir():
+			return nil
+		case keep.Match(rel, false) != nil:
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := cfg.fs.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyToGitWorkTree copies the "kit.adapter.pages" folder content to the work tree.
+func copyToGitWorkTree(workDir string, df *deployFiles) error {
+	for _, file := range df.pagesFiles {
+		data, err := afero.ReadFile(cfg.fs, file)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(workDir, filepath.FromSlash(df.toRemotePath(file)))
+		if err := cfg.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(cfg.fs, dst, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	noJekyll := filepath.Join(workDir, noJekyllFile)
+	if exists, _ := afero.Exists(cfg.fs, noJekyll); !exists {
+		return afero.WriteFile(cfg.fs, noJekyll, []byte{}, 0644)
+	}
+	return nil
+}
+
+// gitDeployCommitMessage returns the commit message with the project and sveltin versions.
+func gitDeployCommitMessage(envName string) (string, error) {
+	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+	projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+	if err != nil {
+		return "", err
+	}
+	projectVersion, err := utils.RetrieveProjectVersion(cfg.fs, pathToPkgFile)
+	if err != nil {
+		return "", err
+	}
+	if projectVersion != "" {
+		projectVersion = " v" + projectVersion
+	}
+	return fmt.Sprintf("Deploy %s%s to %s\n\nBuilt with sveltin v%s", projectName, projectVersion, envName, CliVersion), nil
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+)
+
+var isForceUnlock bool
+
+// deployLock is the struct representing the lock held on the remote folder by the running command.
+type deployLock struct {
+	conn     ftpfs.RemoteServer
+	lock     *ftpfs.Lock
+	folder   string
+	released bool
+}
+
+// acquireDeployLock locks the remote folder. It fails with ExitCodeLocked while a fresh lock
+// is held by another deploy, unless --force-unlock is set.
+func acquireDeployLock(conn ftpfs.RemoteServer, envName string) (*deployLock, error) {
+	l := &deployLock{conn: conn, lock: ftpfs.NewLock(envName), folder: conn.RootFolder()}
+	var previous ftpfs.Lock
+	err := ftpfs.AcquireLockAction(conn, l.lock, isForceUnlock, &previous, isDryRun).Run()
+	var lockedErr *ftpfs.LockedError
+	if errors.As(err, &lockedErr) {
+		return nil, withExitCode(ExitCodeLocked, err)
+	}
+	if err != nil {
+		return nil, withExitCode(ExitCodeTransfer, err)
+	}
+
+	if previous.ID != "" {
+		if previous.IsStale(time.Now()) {
+			cfg.log.Importantf("Replacing the stale lock left by %s@%s on %s", previous.User, previous.Host, previous.CreatedAt.Local().Format(time.RFC1123))
+		} else {
+			cfg.log.Importantf("Forcing the unlock of the remote folder, locked by %s@%s since %s", previous.User, previous.Host, previous.CreatedAt.Local().Format(time.RFC1123))
+		}
+	}
+	return l, nil
+}
+
+// release deletes the lock file from the remote folder, once. On failure, the lock
+// is left on the remote folder and the next deploy needs --force-unlock.
+func (l *deployLock) release() {
+	if l == nil || l.released {
+		return
+	}
+	l.released = true
+	l.conn.SetRootFolder(l.folder)
+	if err := ftpfs.ReleaseLockAction(l.conn, l.lock, isDryRun).Run(); err != nil {
+		cfg.log.Importantf("Could not release the lock on the remote folder, got error '%s'. Use --force-unlock on the next deploy", err.Error())
+	}
+}
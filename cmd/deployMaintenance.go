@@ -0,0 +1,220 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/config"
+	"github.com/sveltinio/sveltin/helpers"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	isMaintenance         bool
+	isMaintenanceHtaccess bool
+)
+
+// Files for the maintenance page. The override ones are looked for within the .sveltin folder,
+// the state one stores what is needed to end the maintenance of an interrupted deploy.
+const (
+	maintenanceIndexFile     string = "index.html"
+	maintenanceHtaccessFile  string = ".htaccess"
+	maintenanceOverrideFile  string = "maintenance.html"
+	maintenanceHtaccessTpl   string = "maintenance.htaccess"
+	maintenanceStateFilename string = "maintenance.json"
+)
+
+// maintenancePage is the struct representing the maintenance page shown while a full deploy
+// replaces the remote folder content. Page and Htaccess are rendered once before deploying.
+type maintenancePage struct {
+	Page     []byte `json:"-"`
+	Htaccess []byte `json:"-"`
+	// WithHtaccess is true when the remote .htaccess file has been replaced by the maintenance one.
+	WithHtaccess bool `json:"htaccess"`
+	// Original is the content of the remote .htaccess file before the deploy, if any.
+	HasOriginal bool   `json:"hasOriginal"`
+	Original    string `json:"original,omitempty"`
+}
+
+// isMaintenanceEnabled returns true if the maintenance page is enabled by the --maintenance flag
+// or by the deploy.maintenance section in sveltin.json, the flag wins when set.
+func isMaintenanceEnabled(cmd *cobra.Command) bool {
+	settings := cfg.projectSettings.Deploy.Maintenance
+	if !cmd.Flags().Changed("maintenance") {
+		isMaintenance = settings.Enabled
+	}
+	if !cmd.Flags().Changed("maintenance-htaccess") {
+		isMaintenanceHtaccess = settings.Htaccess
+	}
+	return isMaintenance || isMaintenanceHtaccess
+}
+
+// newMaintenancePage renders the maintenance page, and the .htaccess rewrite if enabled, with the
+// project name and the current time. The templates set in sveltin.json or stored within the
+// .sveltin folder override the default ones.
+func newMaintenancePage(baseURL string) (*maintenancePage, error) {
+	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+	projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	data := &config.TemplateData{
+		ProjectName: projectName,
+		Maintenance: &tpltypes.MaintenanceData{
+			BaseURL:      baseURL,
+			Timestamp:    now.Format(time.RFC1123),
+			ISOTimestamp: now.UTC().Format(time.RFC3339),
+		},
+	}
+
+	m := &maintenancePage{WithHtaccess: isMaintenanceHtaccess}
+	pageTemplate := cfg.projectSettings.Deploy.Maintenance.Template
+	if pageTemplate == "" {
+		pageTemplate = filepath.Join(cfg.pathMaker.GetRootFolder(), SveltinFolder, maintenanceOverrideFile)
+	}
+	if m.Page, err = renderMaintenanceTemplate(pageTemplate, resources.DeployFilesMap["maintenance"], data); err != nil {
+		return nil, err
+	}
+	if m.WithHtaccess {
+		htaccessTemplate := filepath.Join(cfg.pathMaker.GetRootFolder(), SveltinFolder, maintenanceHtaccessTpl)
+		if m.Htaccess, err = renderMaintenanceTemplate(htaccessTemplate, resources.DeployFilesMap["maintenance_htaccess"], data); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// renderMaintenanceTemplate executes the user template when it exists, the embedded one otherwise.
+func renderMaintenanceTemplate(userTemplate, embeddedTemplate string, data *config.TemplateData) ([]byte, error) {
+	if exists, _ := afero.Exists(cfg.fs, userTemplate); !exists {
+		return helpers.BuildTemplate(embeddedTemplate, nil, data).Run(&resources.SveltinTemplatesFS), nil
+	}
+	content, err := afero.ReadFile(cfg.fs, userTemplate)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(filepath.Base(userTemplate)).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance template '%s', got error '%s'", userTemplate, err.Error())
+	}
+	var writer bytes.Buffer
+	if err := tmpl.Execute(&writer, data); err != nil {
+		return nil, fmt.Errorf("invalid maintenance template '%s', got error '%s'", userTemplate, err.Error())
+	}
+	return writer.Bytes(), nil
+}
+
+// maintenanceStatePath returns the path to the local file storing the state of the maintenance.
+func maintenanceStatePath() string {
+	return filepath.Join(cfg.pathMaker.GetRootFolder(), SveltinFolder, maintenanceStateFilename)
+}
+
+// loadMaintenanceState returns the maintenance started by the interrupted deploy, nil if none.
+func loadMaintenanceState() (*maintenancePage, error) {
+	if exists, _ := afero.Exists(cfg.fs, maintenanceStatePath()); !exists {
+		return nil, nil
+	}
+	content, err := afero.ReadFile(cfg.fs, maintenanceStatePath())
+	if err != nil {
+		return nil, err
+	}
+	m := &maintenancePage{}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("could not parse the maintenance state '%s', got error '%s'", maintenanceStatePath(), err.Error())
+	}
+	return m, nil
+}
+
+// start uploads the maintenance page, and replaces the remote .htaccess file when enabled.
+// The state, with the original .htaccess content, is stored locally so that an interrupted
+// deploy ends the maintenance once resumed.
+func (m *maintenancePage) start(conn ftpfs.RemoteServer) error {
+	cfg.log.Info("Uploading the maintenance page")
+	if m.WithHtaccess {
+		if original, err := conn.ReadFile(maintenanceHtaccessFile); err == nil {
+			m.HasOriginal = true
+			m.Original = string(original)
+		}
+	}
+	if !isDryRun {
+		state, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := afero.WriteFile(cfg.fs, maintenanceStatePath(), state, 0644); err != nil {
+			return err
+		}
+	}
+	if err := conn.WriteFile(maintenanceIndexFile, m.Page, isDryRun); err != nil {
+		return err
+	}
+	if m.WithHtaccess {
+		return conn.WriteFile(maintenanceHtaccessFile, m.Htaccess, isDryRun)
+	}
+	return nil
+}
+
+// end removes what is left of the maintenance once the build content has been uploaded:
+// the maintenance page when the build has no index.html and the maintenance .htaccess file
+// when the build has none, restoring the original one if any.
+func (m *maintenancePage) end(conn ftpfs.RemoteServer, df *deployFiles) error {
+	files := df.toMap()
+	toDelete := []string{}
+	if _, exists := files[maintenanceIndexFile]; !exists {
+		toDelete = append(toDelete, maintenanceIndexFile)
+	}
+	if _, exists := files[maintenanceHtaccessFile]; !exists && m.WithHtaccess {
+		if m.HasOriginal {
+			cfg.log.Info("Restoring the original .htaccess file")
+			if err := conn.WriteFile(maintenanceHtaccessFile, []byte(m.Original), isDryRun); err != nil {
+				return err
+			}
+		} else {
+			toDelete = append(toDelete, maintenanceHtaccessFile)
+		}
+	}
+	if len(toDelete) > 0 {
+		if err := ftpfs.DeleteFilesAction(conn, toDelete, isDryRun).Run(); err != nil {
+			return err
+		}
+	}
+	if isDryRun {
+		return nil
+	}
+	if exists, _ := afero.Exists(cfg.fs, maintenanceStatePath()); exists {
+		return cfg.fs.Remove(maintenanceStatePath())
+	}
+	return nil
+}
+
+// splitLast returns the local pages files without the ones replacing the maintenance page,
+// and these ones, to be uploaded last.
+func (m *maintenancePage) splitLast(df *deployFiles, pagesFiles []string) ([]string, []string) {
+	files, last := []string{}, []string{}
+	for _, file := range pagesFiles {
+		switch df.toRemotePath(file) {
+		case maintenanceIndexFile, maintenanceHtaccessFile:
+			last = append(last, file)
+		default:
+			files = append(files, file)
+		}
+	}
+	return files, last
+}
@@ -0,0 +1,208 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/utils"
+	logger "github.com/sveltinio/yinlog"
+	"golang.org/x/term"
+)
+
+// Exit codes for the deploy commands.
+const (
+	ExitCodeError      int = 1
+	ExitCodeConnection int = 3
+	ExitCodeAuth       int = 4
+	ExitCodeTransfer   int = 5
+	ExitCodeVerify     int = 6
+	ExitCodeLocked     int = 7
+)
+
+// Output formats for the deploy commands.
+const (
+	OutputText string = "text"
+	OutputJSON string = "json"
+)
+
+// deployError is the error returned by the deploy steps, carrying the exit code.
+type deployError struct {
+	code int
+	err  error
+}
+
+func (e *deployError) Error() string {
+	return e.err.Error()
+}
+
+func (e *deployError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode wraps a not nil error with the exit code for the deploy command.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &deployError{code: code, err: err}
+}
+
+// exitCodeOf returns the exit code for the error, ExitCodeError when not set.
+func exitCodeOf(err error) int {
+	var dErr *deployError
+	if errors.As(err, &dErr) {
+		return dErr.code
+	}
+	return ExitCodeError
+}
+
+func exitCodeKind(code int) string {
+	switch code {
+	case ExitCodeConnection:
+		return "connection"
+	case ExitCodeAuth:
+		return "auth"
+	case ExitCodeTransfer:
+		return "transfer"
+	case ExitCodeVerify:
+		return "verify"
+	case ExitCodeLocked:
+		return "locked"
+	default:
+		return "error"
+	}
+}
+
+// deployReport is the struct representing the outcome of a deploy, printed with --output json.
+type deployReport struct {
+	Environment string           `json:"environment"`
+	Mode        string           `json:"mode"`
+	DryRun      bool             `json:"dryRun"`
+	Resumed     bool             `json:"resumed"`
+	Success     bool             `json:"success"`
+	Uploaded    []string         `json:"uploaded"`
+	Deleted     []string         `json:"deleted"`
+	Skipped     []string         `json:"skipped"`
+	Duration    string           `json:"duration"`
+	DurationMs  int64            `json:"durationMs"`
+	Verify      *deployVerify    `json:"verify,omitempty"`
+	Error       *deployReportErr `json:"error,omitempty"`
+	ExitCode    int              `json:"exitCode"`
+	startedAt   time.Time
+}
+
+type deployReportErr struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// deployVerify is the struct representing the outcome of the --verify pass.
+type deployVerify struct {
+	Algorithm  string           `json:"algorithm,omitempty"`
+	Checked    int              `json:"checked"`
+	Missing    []string         `json:"missing"`
+	Extra      []string         `json:"extra"`
+	Mismatched []deployMismatch `json:"mismatched"`
+}
+
+type deployMismatch struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func newDeployVerify(result *ftpfs.VerifyResult) *deployVerify {
+	v := &deployVerify{
+		Algorithm:  result.Algorithm,
+		Checked:    result.Checked,
+		Missing:    result.Missing,
+		Extra:      result.Extra,
+		Mismatched: []deployMismatch{},
+	}
+	for _, m := range result.Mismatched {
+		v.Mismatched = append(v.Mismatched, deployMismatch{Path: m.Path, Reason: m.Reason})
+	}
+	return v
+}
+
+func newDeployReport() *deployReport {
+	return &deployReport{
+		Uploaded:  []string{},
+		Deleted:   []string{},
+		Skipped:   []string{},
+		startedAt: time.Now(),
+	}
+}
+
+// finish sets the duration and the outcome of the deploy.
+func (r *deployReport) finish(err error) {
+	elapsed := time.Since(r.startedAt)
+	r.Duration = elapsed.Round(time.Millisecond).String()
+	r.DurationMs = elapsed.Milliseconds()
+	r.Success = err == nil
+	sort.Strings(r.Uploaded)
+	sort.Strings(r.Deleted)
+	sort.Strings(r.Skipped)
+	if err != nil {
+		r.ExitCode = exitCodeOf(err)
+		r.Error = &deployReportErr{Kind: exitCodeKind(r.ExitCode), Message: err.Error()}
+	}
+}
+
+// print writes the report as indented json to the stdout.
+func (r *deployReport) print() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, string(data))
+	return nil
+}
+
+// exitWithReport prints the report when --output json is set and exits with the exit code for the error.
+func exitWithReport(report *deployReport, err error) {
+	report.finish(err)
+	if outputFormat == OutputJSON {
+		utils.ExitIfError(report.print())
+		if err != nil {
+			os.Exit(report.ExitCode)
+		}
+		return
+	}
+	utils.ExitIfErrorWithCode(err, report.ExitCode)
+}
+
+// isTerminal returns true if the stdout is a terminal.
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// setupDeployOutput validates the --output flag and, when printing json, moves the logs to stderr.
+// It returns true when progress bars and prompts cannot be used.
+func setupDeployOutput() (bool, error) {
+	switch outputFormat {
+	case OutputText:
+	case OutputJSON:
+		if p, ok := cfg.log.Printer.(*logger.TextPrinter); ok {
+			p.Writer = os.Stderr
+		}
+	default:
+		return false, fmt.Errorf("--output must be one of %s, %s, got '%s'", OutputText, OutputJSON, outputFormat)
+	}
+	isPlain := outputFormat == OutputJSON || !isTerminal()
+	if isPlain && !isAutoConfirm {
+		return isPlain, errors.New("not running in a terminal, use --yes to confirm")
+	}
+	return isPlain, nil
+}
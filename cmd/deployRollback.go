@@ -0,0 +1,144 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/activehelps"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/tui/prompts"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+//=============================================================================
+
+var (
+	withBackup string
+)
+
+var deployRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restore a backup archive on the remote server",
+	Long: `Command used to restore the content of the remote folder from one of the backup archives
+created by the deploy command for the deploy environment set by --env.
+
+Without the --to flag, it prompts to select the backup archive from the available ones, newest first.
+When not running in a terminal, both --to and --yes are required.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   DeployRollbackCmdRun,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var comps []string
+		comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument but accepts flags."))
+		return comps, cobra.ShellCompDirectiveDefault
+	},
+}
+
+// DeployRollbackCmdRun is the actual work function.
+func DeployRollbackCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	isPlain, err := setupDeployOutput()
+	utils.ExitIfError(err)
+
+	cfg.log.Plain(markup.H1("Restore a backup on the remote server"))
+
+	err = runRollback(cmd, isPlain)
+	utils.ExitIfErrorWithCode(err, exitCodeOf(err))
+}
+
+// runRollback restores the selected backup archive on the remote folder.
+func runRollback(cmd *cobra.Command, isPlain bool) error {
+	if err := validateDeployFlags(); err != nil {
+		return err
+	}
+
+	env, err := loadDeployEnv(withEnv)
+	if err != nil {
+		return err
+	}
+	applyDeployEnvFlags(cmd, env)
+
+	backups, err := getEnvBackups(env.name)
+	if err != nil {
+		return err
+	}
+	if isPlain && withBackup == "" {
+		return errors.New("not running in a terminal, use --to to select the backup archive")
+	}
+	backup, err := prompts.SelectBackupHandler(backups, withBackup)
+	if err != nil {
+		return err
+	}
+
+	remoteConn, err := newRemoteServer(env, isPlain)
+	if err != nil {
+		return err
+	}
+	if err := connectRemoteServer(remoteConn); err != nil {
+		return err
+	}
+	isDisconnected := false
+	defer disconnectRemoteServer(remoteConn, &isDisconnected)
+
+	feedbacks.ShowDeployRollbackWarningMessages(backup.Name)
+	if isDryRun {
+		feedbacks.ShowDryRunMessage()
+	}
+
+	isConfirm, err := confirmDeploy()
+	if err != nil || !isConfirm {
+		return err
+	}
+
+	// prevent concurrent deploys to the same remote folder
+	lock, err := acquireDeployLock(remoteConn, env.name)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	cfg.log.Infof("Restoring '%s' on the remote folder", backup.Name)
+	if err := ftpfs.RestoreAction(remoteConn, cfg.fs, backup.Path, keepRules(), isDryRun).Run(); err != nil {
+		return withExitCode(ExitCodeTransfer, err)
+	}
+
+	// the lock must be released before closing the connection
+	lock.release()
+
+	// close the connection
+	isDisconnected = true
+	if err := ftpfs.LogoutAction(remoteConn).Run(); err != nil {
+		return withExitCode(ExitCodeConnection, err)
+	}
+
+	cfg.log.Success("Done\n")
+	return nil
+}
+
+func deployRollbackCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withBackup, "to", "", "name of the backup archive to restore")
+	cmd.Flags().BoolVarP(&isDryRun, "dryRun", "d", false, "dry run")
+	cmd.Flags().BoolVarP(&isAutoConfirm, "yes", "y", false, "do not ask for confirmation, required when not running in a terminal")
+	cmd.Flags().StringVar(&withEnv, "env", DefaultDeployEnv, "name of the deploy environment")
+	cmd.Flags().BoolVar(&isForceUnlock, "force-unlock", false, "replace the lock held on the remote folder by another deploy")
+	cmd.Flags().IntVarP(&uploadConcurrency, "concurrency", "c", 1, "number of connections used to upload files in parallel")
+	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "list of files to not be deleted from the remote server. Default: .htaccess")
+	cmd.Flags().StringVar(&withExcludeFile, "withExcludeFile", "", "path to the file containing the list of files to not be deleted from the remote server")
+}
+
+func init() {
+	deployRollbackCmdFlags(deployRollbackCmd)
+	deployCmd.AddCommand(deployRollbackCmd)
+}
@@ -9,7 +9,10 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/helpers"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
 	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/utils"
 )
 
 //=============================================================================
@@ -17,13 +20,17 @@ import (
 var generateCmd = &cobra.Command{
 	Use:     "generate",
 	Aliases: []string{"g"},
-	Short:   "Generate static files (sitemap, rss, menu)",
+	Short:   "Generate static files (sitemap, rss, feed, menu)",
 	Long: resources.GetASCIIArt() + `
 Command used to generate static files through its own subcommands.
 
+Each subcommand (feed, menu, rss and sitemap) reads the front matter of every content file
+(index.svx, index.md or index.svelte.md) within content/<resource>/<name>. A malformed front
+matter stops the generation, reporting the file and the line.
+
 Run 'sveltin generate -h' for further details.
 `,
-	ValidArgs:             []string{"menu", "rss", "sitemap"},
+	ValidArgs:             []string{"feed", "menu", "rss", "sitemap"},
 	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 	DisableFlagsInUseLine: true,
 }
@@ -31,3 +38,13 @@ Run 'sveltin generate -h' for further details.
 func init() {
 	rootCmd.AddCommand(generateCmd)
 }
+
+//=============================================================================
+
+// loadContentIndex returns the front matter of all the resources contents.
+// It exits listing the file and line of each malformed front matter, if any.
+func loadContentIndex(existingResources []string) *tpltypes.ContentIndex {
+	index, err := helpers.BuildContentIndex(cfg.fs, existingResources, cfg.settings.GetContentPath())
+	utils.ExitIfError(err)
+	return index
+}
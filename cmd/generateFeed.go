@@ -0,0 +1,152 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/helpers"
+	"github.com/sveltinio/sveltin/helpers/factory"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+var (
+	feedLimit  int
+	feedFormat string
+)
+
+// feedFormatData is the struct representing a feed format, its template and the generated file.
+type feedFormatData struct {
+	templateID string
+	filename   string
+}
+
+// feedFormats are the supported feed formats, "all" generates them all.
+var feedFormats = map[string]feedFormatData{
+	"rss":  {templateID: "rss", filename: "rss.xml"},
+	"atom": {templateID: "atom", filename: "atom.xml"},
+	"json": {templateID: "jsonfeed", filename: "feed.json"},
+}
+
+var feedFormatNames = []string{"rss", "atom", "json"}
+
+//=============================================================================
+
+var generateFeedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Generate the RSS, Atom and JSON feeds for your Sveltin project",
+	Long: resources.GetASCIIArt() + `
+Command used to generate the feeds for your website within the static folder:
+
+  - rss:  RSS 2.0 (rss.xml)
+  - atom: Atom 1.0 (atom.xml)
+  - json: JSON Feed 1.1 (feed.json)
+
+Use the --format flag to choose one of them, all of them are generated by default.
+
+All the formats share the same items, the published contents of all the resources (draft: true
+excluded), newest first, and the same channel values (see 'sveltin generate rss -h').
+Item IDs are derived from the base URL, the resource and the slug, so that they do not change
+across builds.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RunGenerateFeedCmd,
+}
+
+// RunGenerateFeedCmd is the actual work function.
+func RunGenerateFeedCmd(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	formats, err := selectedFeedFormats(feedFormat)
+	utils.ExitIfError(err)
+
+	cfg.log.Plain(markup.H1("Generating the feed files"))
+
+	cfg.log.Info("Getting list of all resources contents")
+	existingResources := helpers.GetAllResources(cfg.fs, cfg.pathMaker.GetPathToExistingResources())
+
+	cfg.log.Info("Parsing the front matter of all resources contents")
+	contentIndex := loadContentIndex(existingResources)
+
+	// GET FOLDER: static
+	staticFolder := cfg.fsManager.GetFolder(StaticFolder)
+
+	for _, name := range formats {
+		format := feedFormats[name]
+		feed := newFeedData(cmd, contentIndex, format.filename)
+		// NEW FILE: static/<feed file>
+		cfg.log.Infof("Saving the %s file (%d items) to the static folder", format.filename, len(feed.Items))
+		staticFolder.Add(cfg.fsManager.NewFeedFile(format.templateID, format.filename, &cfg.projectSettings, feed, contentIndex))
+	}
+
+	// SET FOLDER STRUCTURE
+	projectFolder := cfg.fsManager.GetFolder(RootFolder)
+	projectFolder.Add(staticFolder)
+
+	// GENERATE THE FOLDER TREE
+	sfs := factory.NewNoPageArtifact(&resources.SveltinTemplatesFS, cfg.fs)
+	err = projectFolder.Create(sfs)
+	utils.ExitIfError(err)
+
+	cfg.log.Success("Done\n")
+}
+
+func feedCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&feedLimit, "limit", helpers.DefaultFeedLimit, "maximum number of items within the feed, 0 for all")
+}
+
+func init() {
+	generateCmd.AddCommand(generateFeedCmd)
+	feedCmdFlags(generateFeedCmd)
+	generateFeedCmd.Flags().StringVar(&feedFormat, "format", "all", "feed format: rss, atom, json or all")
+}
+
+//=============================================================================
+
+// selectedFeedFormats returns the names of the feed formats for the --format value.
+func selectedFeedFormats(value string) ([]string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "all" {
+		return feedFormatNames, nil
+	}
+	if _, ok := feedFormats[value]; !ok {
+		return nil, fmt.Errorf("invalid --format value '%s', expected one of %s or all", value, strings.Join(feedFormatNames, ", "))
+	}
+	return []string{value}, nil
+}
+
+// newFeedData returns the feed for the published contents.
+func newFeedData(cmd *cobra.Command, index *tpltypes.ContentIndex, feedFile string) *tpltypes.FeedData {
+	return helpers.NewFeedData(cfg.fs, &cfg.projectSettings, readWebsiteConfig(), index, cfg.pathMaker.GetStaticFolder(), feedFile, feedItemsLimit(cmd))
+}
+
+// newResourceFeedData returns the feed for the published contents of the resource.
+func newResourceFeedData(cmd *cobra.Command, index *tpltypes.ContentIndex, resource, feedFile string) *tpltypes.FeedData {
+	return helpers.NewResourceFeedData(cfg.fs, &cfg.projectSettings, readWebsiteConfig(), index, resource, cfg.pathMaker.GetStaticFolder(), feedFile, feedItemsLimit(cmd))
+}
+
+// feedItemsLimit returns the maximum number of items within a feed. The --limit flag, when set,
+// takes precedence over the rss limit within sveltin.json.
+func feedItemsLimit(cmd *cobra.Command) int {
+	if !cmd.Flags().Changed("limit") && cfg.projectSettings.RSS.Limit > 0 {
+		return cfg.projectSettings.RSS.Limit
+	}
+	return feedLimit
+}
+
+func readWebsiteConfig() map[string]string {
+	return helpers.ReadWebsiteConfig(cfg.fs, filepath.Join(cfg.pathMaker.GetConfigFolder(), WebSiteTSFile))
+}
@@ -47,6 +47,9 @@ func RunGenerateMenuCmd(cmd *cobra.Command, args []string) {
 	existingResources := helpers.GetAllResources(cfg.fs, cfg.settings.GetContentPath())
 	contents := helpers.GetResourceContentMap(cfg.fs, existingResources, cfg.settings.GetContentPath())
 
+	cfg.log.Info("Parsing the front matter of all resources contents")
+	contentIndex := loadContentIndex(existingResources)
+
 	cfg.log.Info("Getting list of all routes")
 	allRoutes := helpers.GetAllRoutes(cfg.fs, cfg.pathMaker.GetPathToRoutes())
 
@@ -55,7 +58,7 @@ func RunGenerateMenuCmd(cmd *cobra.Command, args []string) {
 
 	// ADD FILE: config/menu.js
 	cfg.log.Info("Saving the menu.js.ts file")
-	menuFile := cfg.fsManager.NewMenuFile("menu", allRoutes, contents, withContentFlag)
+	menuFile := cfg.fsManager.NewMenuFile("menu", allRoutes, contents, contentIndex, withContentFlag)
 	configFolder.Add(menuFile)
 
 	// SET FOLDER STRUCTURE
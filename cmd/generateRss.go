@@ -11,18 +11,50 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/sveltinio/sveltin/helpers"
 	"github.com/sveltinio/sveltin/helpers/factory"
+	"github.com/sveltinio/sveltin/internal/composer"
 	"github.com/sveltinio/sveltin/internal/markup"
 	"github.com/sveltinio/sveltin/resources"
 	"github.com/sveltinio/sveltin/utils"
 )
 
+var (
+	perResourceFlag bool
+)
+
 //=============================================================================
 
 var generateRssCmd = &cobra.Command{
 	Use:   "rss",
 	Short: "Generate the RSS feed for your Sveltin project",
 	Long: resources.GetASCIIArt() + `
-Command used to generate the RSS feed (rss.xml) file for your website.
+Command used to generate the RSS 2.0 feed (rss.xml) file for your website.
+
+Items are the published contents of all the resources (draft: true excluded), newest first,
+with title, headline, author, created_at and cover (as enclosure) from their front matter.
+
+The channel title, description, language and copyright are read from the "rss" section of
+sveltin.json, falling back to the values within config/website.js.ts:
+
+  "rss": {
+    "title": "My Website",
+    "description": "Latest news",
+    "language": "en-GB",
+    "copyright": "2023 - My Website",
+    "managingEditor": "editor@example.com (Jane Doe)",
+    "limit": 20
+  }
+
+The number of items is 20 by default, use the --limit flag (0 for all) or the "limit" value to change it.
+
+The --per-resource flag writes a feed for each resource too, as static/<resource>/rss.xml, next to
+the aggregate one. Their title and description can be set by resource, as well as opting out:
+
+  "rss": {
+    "resources": {
+      "posts": { "title": "My Website - Blog", "description": "Latest blog posts" },
+      "projects": { "exclude": true }
+    }
+  }
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
@@ -38,19 +70,35 @@ func RunGenerateRSSCmd(cmd *cobra.Command, args []string) {
 
 	cfg.log.Info("Getting list of all resources contents")
 	existingResources := helpers.GetAllResources(cfg.fs, cfg.pathMaker.GetPathToExistingResources())
-	contents := helpers.GetResourceContentMap(cfg.fs, existingResources, cfg.settings.GetContentPath())
 
-	cfg.log.Info("Getting list of all routes")
-	allRoutes := helpers.GetAllRoutes(cfg.fs, cfg.pathMaker.GetPathToRoutes())
+	cfg.log.Info("Parsing the front matter of all resources contents")
+	contentIndex := loadContentIndex(existingResources)
+	feed := newFeedData(cmd, contentIndex, feedFormats["rss"].filename)
+	cfg.log.Infof("Adding %d items to the feed", len(feed.Items))
 
 	// GET FOLDER: static
 	staticFolder := cfg.fsManager.GetFolder(StaticFolder)
 
 	// NEW FILE: static/rss.xml
 	cfg.log.Info("Saving the file to the static folder")
-	rssFile := cfg.fsManager.NewNoPageFile("rss", &cfg.projectSettings, allRoutes, contents)
+	rssFile := cfg.fsManager.NewFeedFile("rss", feedFormats["rss"].filename, &cfg.projectSettings, feed, contentIndex)
 	staticFolder.Add(rssFile)
 
+	if perResourceFlag {
+		for _, resource := range existingResources {
+			if cfg.projectSettings.RSS.Resources[resource].Exclude {
+				cfg.log.Infof("Skipping the %s feed, excluded within sveltin.json", resource)
+				continue
+			}
+			resourceFeed := newResourceFeedData(cmd, contentIndex, resource, feedFormats["rss"].filename)
+			// NEW FILE: static/<resource>/rss.xml
+			cfg.log.Infof("Saving the %s/rss.xml file (%d items) to the static folder", resource, len(resourceFeed.Items))
+			resourceFolder := composer.NewFolder(resource)
+			resourceFolder.Add(cfg.fsManager.NewFeedFile("rss", feedFormats["rss"].filename, &cfg.projectSettings, resourceFeed, contentIndex))
+			staticFolder.Add(resourceFolder)
+		}
+	}
+
 	// SET FOLDER STRUCTURE
 	projectFolder := cfg.fsManager.GetFolder(RootFolder)
 	projectFolder.Add(staticFolder)
@@ -65,4 +113,6 @@ func RunGenerateRSSCmd(cmd *cobra.Command, args []string) {
 
 func init() {
 	generateCmd.AddCommand(generateRssCmd)
+	feedCmdFlags(generateRssCmd)
+	generateRssCmd.Flags().BoolVar(&perResourceFlag, "per-resource", false, "write a feed for each resource too, as static/<resource>/rss.xml")
 }
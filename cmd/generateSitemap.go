@@ -23,6 +23,25 @@ var generateSitemapCmd = &cobra.Command{
 	Short: "Generate the sitemap file for your Sveltin project",
 	Long: resources.GetASCIIArt() + `
 Command used to generate the sitemap (sitemap.xml) file for your website.
+
+It lists the home page, the routes and the published contents of the resources (draft: true
+excluded). The lastmod value of a content is its updated_at front matter value, the content file
+modification time otherwise; the one of a resource page is the latest among its contents.
+
+The changefreq and priority values from the "sitemap" section of sveltin.json can be overridden
+by resource and by route, a route taking precedence over its resource:
+
+  "sitemap": {
+    "changeFreq": "weekly",
+    "priority": 0.5,
+    "resources": {
+      "posts": { "changeFreq": "daily", "priority": 0.8 }
+    },
+    "routes": {
+      "about": { "changeFreq": "yearly", "priority": 0.3 },
+      "posts/hello": { "priority": 1.0 }
+    }
+  }
 `,
 	DisableFlagsInUseLine: true,
 	Args:                  cobra.ExactArgs(0),
@@ -37,18 +56,22 @@ func RunGenerateSitemapCmd(cmd *cobra.Command, args []string) {
 	cfg.log.Plain(markup.H1("Generating the sitemap file"))
 
 	cfg.log.Info("Getting list of all resources contents")
-	existingResources := helpers.GetAllResources(cfg.fs, cfg.settings.GetContentPath())
-	contents := helpers.GetResourceContentMap(cfg.fs, existingResources, cfg.settings.GetContentPath())
+	existingResources := helpers.GetAllResources(cfg.fs, cfg.pathMaker.GetPathToExistingResources())
+
+	cfg.log.Info("Parsing the front matter of all resources contents")
+	contentIndex := loadContentIndex(existingResources)
 
 	cfg.log.Info("Getting list of all routes")
 	allRoutes := helpers.GetAllRoutes(cfg.fs, cfg.pathMaker.GetPathToRoutes())
+	urls := helpers.NewSitemapURLs(cfg.fs, &cfg.projectSettings, allRoutes, contentIndex, cfg.pathMaker.GetPathToRoutes())
+	cfg.log.Infof("Adding %d urls to the sitemap", len(urls))
 
 	// GET FOLDER: static
 	staticFolder := cfg.fsManager.GetFolder(StaticFolder)
 
-	// NEW FILE: static/rss.xml
+	// NEW FILE: static/sitemap.xml
 	cfg.log.Info("Saving the file to the static folder")
-	sitemapFile := cfg.fsManager.NewNoPageFile("sitemap", &cfg.projectSettings, allRoutes, contents)
+	sitemapFile := cfg.fsManager.NewSitemapFile(&cfg.projectSettings, urls, contentIndex)
 	staticFolder.Add(sitemapFile)
 
 	// SET FOLDER STRUCTURE
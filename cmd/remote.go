@@ -0,0 +1,62 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/resources"
+)
+
+//=============================================================================
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Inspect the remote folder (ls, du, get, diff)",
+	Long: resources.GetASCIIArt() + `
+Command used to inspect the remote folder of a deploy environment through its own subcommands.
+The connection is set up as for the deploy command and nothing is ever changed on the remote server.
+
+Run 'sveltin remote -h' for further details.
+`,
+	ValidArgs:             []string{"ls", "du", "get", "diff"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	remoteCmd.PersistentFlags().StringVar(&withEnv, "env", DefaultDeployEnv, "name of the deploy environment")
+	rootCmd.AddCommand(remoteCmd)
+}
+
+//=============================================================================
+
+// openRemoteServer connects and logs in to the remote server for the deploy environment.
+func openRemoteServer() (ftpfs.RemoteServer, error) {
+	env, err := loadDeployEnv(withEnv)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := newRemoteServer(env, !isTerminal())
+	if err != nil {
+		return nil, err
+	}
+	if err := connectRemoteServer(conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// listRemoteFiles returns the files within the remote folder matching the path.
+func listRemoteFiles(conn ftpfs.RemoteServer, name string) ([]ftpfs.RemoteFile, error) {
+	var files []ftpfs.RemoteFile
+	if err := ftpfs.ListFilesAction(conn, &files).Run(); err != nil {
+		return nil, err
+	}
+	return ftpfs.FilterRemoteFiles(files, name), nil
+}
@@ -0,0 +1,68 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+//=============================================================================
+
+var remoteDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the remote folder with the local build",
+	Long: `Command used to compare the remote folder with the local build folder, as the deploy --verify flag does.
+
+Files missing on the remote folder, extra files and files with a different size (or checksum, when the
+FTP server supports HASH, XCRC or MD5) are reported. Paths matching the .sveltinignore rules and the
+--exclude flag are not reported as extra.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   RemoteDiffCmdRun,
+}
+
+// RemoteDiffCmdRun is the actual work function.
+func RemoteDiffCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Compare the remote folder with the local build"))
+
+	var err error
+	ignoreRules, err = ftpfs.LoadIgnoreFile(cfg.fs, filepath.Join(cfg.pathMaker.GetRootFolder(), ftpfs.IgnoreFilename))
+	utils.ExitIfError(err)
+
+	deployFiles, err := newDeployFiles(cfg.projectSettings.SvelteKit.Adapter, ignoreRules)
+	utils.ExitIfError(err)
+
+	remoteConn, err := openRemoteServer()
+	utils.ExitIfError(err)
+
+	var result ftpfs.VerifyResult
+	utils.ExitIfError(ftpfs.VerifyAction(remoteConn, cfg.fs, deployFiles.toMap(), keepRules(), &result).Run())
+
+	utils.ExitIfError(ftpfs.LogoutAction(remoteConn).Run())
+
+	feedbacks.ShowVerifyResult("Diff summary", &result)
+}
+
+func remoteDiffCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&withExclude, "exclude", "e", []string{".htaccess"}, "list of files on the remote folder not to be reported as extra. Default: .htaccess")
+}
+
+func init() {
+	remoteDiffCmdFlags(remoteDiffCmd)
+	remoteCmd.AddCommand(remoteDiffCmd)
+}
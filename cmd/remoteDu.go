@@ -0,0 +1,70 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+//=============================================================================
+
+var (
+	withDepth int
+)
+
+var remoteDuCmd = &cobra.Command{
+	Use:   "du [path]",
+	Short: "Show the disk usage of the remote folders",
+	Long: `Command used to show the size and the number of files of the remote folder, or the path
+relative to it, and its subfolders. Use --depth to set how many levels of subfolders are shown.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.MaximumNArgs(1),
+	Run:                   RemoteDuCmdRun,
+}
+
+// RemoteDuCmdRun is the actual work function.
+func RemoteDuCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Show the remote disk usage"))
+
+	if withDepth < 0 {
+		utils.ExitIfError(fmt.Errorf("--depth cannot be negative, got %d", withDepth))
+	}
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	remoteConn, err := openRemoteServer()
+	utils.ExitIfError(err)
+
+	files, err := listRemoteFiles(remoteConn, name)
+	utils.ExitIfError(err)
+
+	utils.ExitIfError(ftpfs.LogoutAction(remoteConn).Run())
+
+	feedbacks.ShowDiskUsage(fmt.Sprintf("Disk usage for %s", remoteConn.RootFolder()), ftpfs.DiskUsage(files, name, withDepth))
+}
+
+func remoteDuCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&withDepth, "depth", "D", 1, "number of levels of subfolders to show")
+}
+
+func init() {
+	remoteDuCmdFlags(remoteDuCmd)
+	remoteCmd.AddCommand(remoteDuCmd)
+}
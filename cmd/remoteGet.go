@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+//=============================================================================
+
+var (
+	withDownloadDir string
+	isForce         bool
+)
+
+var remoteGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "Download a file or a folder from the remote server",
+	Long: `Command used to download a single file, or a folder with all its content, from the remote folder.
+
+The path is relative to the remote folder and the files are saved within the folder set by --to
+(default: the current one), e.g. 'sveltin remote get posts' saves the remote files as ./posts/...
+Existing local files are not overwritten unless --force is set.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Run:                   RemoteGetCmdRun,
+}
+
+// RemoteGetCmdRun is the actual work function.
+func RemoteGetCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Download from the remote server"))
+
+	name := strings.Trim(path.Clean("/"+filepath.ToSlash(args[0])), "/")
+
+	remoteConn, err := openRemoteServer()
+	utils.ExitIfError(err)
+
+	files, err := listRemoteFiles(remoteConn, name)
+	utils.ExitIfError(err)
+	if len(files) == 0 {
+		utils.ExitIfError(fmt.Errorf("'%s' not found on the remote folder %s", name, remoteConn.RootFolder()))
+	}
+
+	// files are saved relative to the parent folder of the path
+	basePath := path.Dir(name)
+	if basePath == "." {
+		basePath = ""
+	}
+	if !isForce {
+		for _, f := range files {
+			rel := f.Path
+			if basePath != "" {
+				rel = strings.TrimPrefix(rel, basePath+"/")
+			}
+			localFile := filepath.Join(withDownloadDir, filepath.FromSlash(rel))
+			if exists, _ := afero.Exists(cfg.fs, localFile); exists {
+				utils.ExitIfError(fmt.Errorf("'%s' already exists, use --force to overwrite it", localFile))
+			}
+		}
+	}
+
+	cfg.log.Infof("Downloading %d files to '%s'", len(files), withDownloadDir)
+	utils.ExitIfError(ftpfs.DownloadAction(remoteConn, cfg.fs, files, basePath, withDownloadDir).Run())
+
+	utils.ExitIfError(ftpfs.LogoutAction(remoteConn).Run())
+	cfg.log.Success("Done\n")
+}
+
+func remoteGetCmdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&withDownloadDir, "to", ".", "local folder to save the files to")
+	cmd.Flags().BoolVarP(&isForce, "force", "f", false, "overwrite existing local files")
+}
+
+func init() {
+	remoteGetCmdFlags(remoteGetCmd)
+	remoteCmd.AddCommand(remoteGetCmd)
+}
@@ -0,0 +1,56 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/tui/feedbacks"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+//=============================================================================
+
+var remoteLsCmd = &cobra.Command{
+	Use:   "ls [path]",
+	Short: "List the files within the remote folder",
+	Long: `Command used to list the files, with their size and modification time, within the remote folder
+or the path relative to it.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.MaximumNArgs(1),
+	Run:                   RemoteLsCmdRun,
+}
+
+// RemoteLsCmdRun is the actual work function.
+func RemoteLsCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("List the remote files"))
+
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	remoteConn, err := openRemoteServer()
+	utils.ExitIfError(err)
+
+	files, err := listRemoteFiles(remoteConn, name)
+	utils.ExitIfError(err)
+
+	utils.ExitIfError(ftpfs.LogoutAction(remoteConn).Run())
+
+	feedbacks.ShowRemoteFiles(remoteConn.RootFolder(), files)
+}
+
+func init() {
+	remoteCmd.AddCommand(remoteLsCmd)
+}
@@ -57,6 +57,7 @@ const (
 // Folder names for a Sveltin project structure.
 const (
 	RootFolder    string = "root"
+	SveltinFolder string = ".sveltin"
 	BackupsFolder string = "backups"
 	ConfigFolder  string = "config"
 	ContentFolder string = "content"
@@ -0,0 +1,279 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sveltinio/sveltin/internal/markup"
+	"github.com/sveltinio/sveltin/internal/secrets"
+	"github.com/sveltinio/sveltin/resources"
+	"github.com/sveltinio/sveltin/tui/activehelps"
+	"github.com/sveltinio/sveltin/utils"
+	"golang.org/x/term"
+)
+
+// FTPPasswordEnvVar is the environment variable with the FTP password, it takes precedence over any other source.
+const FTPPasswordEnvVar string = "SVELTIN_FTP_PASSWORD"
+
+//=============================================================================
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the FTP passwords within the local encrypted store (set, get, rm)",
+	Long: resources.GetASCIIArt() + `
+Command used to store the FTP password of a deploy environment within a local encrypted store,
+so that it does not need to be written in plain text within the .env.<name> files.
+
+The store is the secrets.json file within the sveltin folder of the user configuration directory
+(e.g. ~/.config/sveltin/secrets.json), or the file set by the SVELTIN_SECRETS_FILE environment
+variable. Values are encrypted with a key derived from a passphrase, prompted for or read from
+the SVELTIN_SECRETS_PASSPHRASE environment variable. Passwords are stored per project and
+environment (--env, default: production).
+
+The deploy commands resolve the FTP password, in order, from:
+  1. the SVELTIN_FTP_PASSWORD environment variable;
+  2. FTP_PASSWORD in the .env.<name> file of the environment;
+  3. the local encrypted store;
+  4. a hidden prompt, when running in a terminal.
+
+Run 'sveltin secrets -h' for further details.
+`,
+	ValidArgs:             []string{"set", "get", "rm"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Store the FTP password of a deploy environment",
+	Long: `Command used to store the FTP password of a deploy environment within the local encrypted store.
+
+The password is prompted for, without echoing it, or read from the standard input when it is not
+a terminal (e.g. 'pass show ftp | sveltin secrets set'). It is never accepted as an argument, not to
+be saved within the shell history. The store is created on first use.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   SecretsSetCmdRun,
+	ValidArgsFunction:     noArgsButFlags,
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Print the FTP password of a deploy environment",
+	Long: `Command used to print the FTP password of a deploy environment, as stored within the local
+encrypted store, to the standard output.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   SecretsGetCmdRun,
+	ValidArgsFunction:     noArgsButFlags,
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:   "rm",
+	Short: "Remove the FTP password of a deploy environment",
+	Long: `Command used to remove the FTP password of a deploy environment from the local encrypted store.
+`,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(0),
+	Run:                   SecretsRmCmdRun,
+	ValidArgsFunction:     noArgsButFlags,
+}
+
+// SecretsSetCmdRun is the actual work function.
+func SecretsSetCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	cfg.log.Plain(markup.H1("Storing the FTP password"))
+
+	name, err := secretName(withEnv)
+	utils.ExitIfError(err)
+	store, err := openSecretsStore()
+	utils.ExitIfError(err)
+	utils.ExitIfError(unlockSecretsStore(store))
+
+	password, err := readSecret(fmt.Sprintf("FTP password for the '%s' environment: ", withEnv))
+	utils.ExitIfError(err)
+	if password == "" {
+		utils.ExitIfError(errors.New("the password cannot be empty"))
+	}
+	utils.ExitIfError(store.Set(name, password))
+	utils.ExitIfError(store.Save())
+
+	cfg.log.Successf("The FTP password for '%s' has been stored in %s\n", name, store.Path())
+}
+
+// SecretsGetCmdRun is the actual work function.
+func SecretsGetCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	name, err := secretName(withEnv)
+	utils.ExitIfError(err)
+	store, err := openSecretsStore()
+	utils.ExitIfError(err)
+	if !store.Has(name) {
+		utils.ExitIfError(fmt.Errorf("no FTP password stored for '%s' in %s", name, store.Path()))
+	}
+	utils.ExitIfError(unlockSecretsStore(store))
+
+	password, err := store.Get(name)
+	utils.ExitIfError(err)
+	fmt.Println(password)
+}
+
+// SecretsRmCmdRun is the actual work function.
+func SecretsRmCmdRun(cmd *cobra.Command, args []string) {
+	// Exit if running sveltin commands either from a not valid directory or not latest sveltin version.
+	isValidProject(true)
+
+	name, err := secretName(withEnv)
+	utils.ExitIfError(err)
+	store, err := openSecretsStore()
+	utils.ExitIfError(err)
+	if !store.Has(name) {
+		cfg.log.Infof("No FTP password stored for '%s'", name)
+		return
+	}
+	// the passphrase is checked before any change, not to let anyone empty the store
+	utils.ExitIfError(unlockSecretsStore(store))
+	store.Remove(name)
+	utils.ExitIfError(store.Save())
+
+	cfg.log.Successf("The FTP password for '%s' has been removed from %s\n", name, store.Path())
+}
+
+func init() {
+	secretsCmd.PersistentFlags().StringVar(&withEnv, "env", DefaultDeployEnv, "name of the deploy environment")
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+	secretsCmd.AddCommand(secretsRmCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+//=============================================================================
+
+func noArgsButFlags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var comps []string
+	comps = cobra.AppendActiveHelp(comps, activehelps.Hint("[WARN] This command does not take any argument but accepts flags."))
+	return comps, cobra.ShellCompDirectiveDefault
+}
+
+// secretName returns the name of the FTP password within the store for the project and the environment.
+func secretName(envName string) (string, error) {
+	pathToPkgFile := filepath.Join(cfg.pathMaker.GetRootFolder(), "package.json")
+	projectName, err := utils.RetrieveProjectName(cfg.fs, pathToPkgFile)
+	if err != nil {
+		return "", err
+	}
+	return projectName + "/" + strings.ToLower(envName), nil
+}
+
+func openSecretsStore() (*secrets.Store, error) {
+	path, err := secrets.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Open(cfg.fs, path)
+}
+
+// unlockSecretsStore unlocks the store with the passphrase from the SVELTIN_SECRETS_PASSPHRASE
+// environment variable, prompting for it otherwise. A new store asks to confirm it.
+func unlockSecretsStore(store *secrets.Store) error {
+	if passphrase := os.Getenv(secrets.PassphraseEnvVar); passphrase != "" {
+		return store.Unlock(passphrase)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("set the %s environment variable to unlock the secrets store when not running in a terminal", secrets.PassphraseEnvVar)
+	}
+
+	passphrase, err := readSecret("Passphrase for the secrets store: ")
+	if err != nil {
+		return err
+	}
+	if len(store.Names()) == 0 {
+		confirmed, err := readSecret("Confirm the passphrase: ")
+		if err != nil {
+			return err
+		}
+		if confirmed != passphrase {
+			return errors.New("the passphrases do not match")
+		}
+	}
+	return store.Unlock(passphrase)
+}
+
+// readSecret prompts for a value without echoing it. When the standard input is not a terminal,
+// the first line is read from it instead.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("could not read the secret from the standard input")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// resolveFTPPassword sets the FTP password of the environment from the first source having it:
+// the SVELTIN_FTP_PASSWORD environment variable, the FTP_PASSWORD value of the environment, the
+// local encrypted store and, if allowed and running in a terminal, a hidden prompt.
+// Only the source is logged, never the password.
+func resolveFTPPassword(env *deployEnv, allowPrompt bool) error {
+	if password := os.Getenv(FTPPasswordEnvVar); password != "" {
+		env.data.FTPPassword = password
+		cfg.log.Infof("Using the FTP password from the %s environment variable", FTPPasswordEnvVar)
+		return nil
+	}
+	if env.data.FTPPassword != "" {
+		return nil
+	}
+
+	name, err := secretName(env.name)
+	if err != nil {
+		return err
+	}
+	store, err := openSecretsStore()
+	if err != nil {
+		return err
+	}
+	if store.Has(name) {
+		if err := unlockSecretsStore(store); err != nil {
+			return err
+		}
+		if env.data.FTPPassword, err = store.Get(name); err != nil {
+			return err
+		}
+		cfg.log.Infof("Using the FTP password from the secrets store (%s)", store.Path())
+		return nil
+	}
+
+	if allowPrompt && term.IsTerminal(int(os.Stdin.Fd())) {
+		env.data.FTPPassword, err = readSecret(fmt.Sprintf("FTP password for %s@%s: ", env.data.FTPUser, env.data.FTPHost))
+		return err
+	}
+	return nil
+}
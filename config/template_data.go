@@ -25,4 +25,7 @@ type TemplateData struct {
 	NoPage          *tpltypes.NoPageData
 	Theme           *tpltypes.ThemeData
 	Misc            *tpltypes.MiscFileData
+	Maintenance     *tpltypes.MaintenanceData
+	Precompress     *tpltypes.PrecompressData
+	ContentIndex    *tpltypes.ContentIndex
 }
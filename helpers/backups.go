@@ -0,0 +1,137 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package helpers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/common"
+)
+
+// BackupFile is the struct representing a tar archive stored within the backups folder.
+type BackupFile struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// GetAllBackups returns the list of backup archives within the folder, newest first.
+func GetAllBackups(fs afero.Fs, path string) ([]BackupFile, error) {
+	backups := []BackupFile{}
+	if !common.DirExists(fs, path) {
+		return backups, nil
+	}
+
+	files, err := afero.ReadDir(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".tar.gz") {
+			backups = append(backups, BackupFile{
+				Name:    f.Name(),
+				Path:    filepath.Join(path, f.Name()),
+				Size:    f.Size(),
+				ModTime: f.ModTime(),
+			})
+		}
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].ModTime.After(backups[j].ModTime)
+	})
+	return backups, nil
+}
+
+// BackupEntry is the struct representing a file within a backup archive.
+type BackupEntry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// GetBackupEntries returns the list of the files within the backup archive.
+func GetBackupEntries(fs afero.Fs, path string) ([]BackupEntry, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open tarball file '%s', got error '%s'", path, err.Error())
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read tarball file '%s', got error '%s'", path, err.Error())
+	}
+	defer gzipReader.Close()
+
+	entries := []BackupEntry{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read tarball file '%s', got error '%s'", path, err.Error())
+		}
+		if header.Typeflag == tar.TypeReg {
+			entries = append(entries, BackupEntry{Name: header.Name, Size: header.Size, ModTime: header.ModTime})
+		}
+	}
+	return entries, nil
+}
+
+// BackupRetention is the struct representing the retention policies for the backup archives.
+// Zero values disable the policy.
+type BackupRetention struct {
+	Keep    int
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// IsEmpty returns true if no policy is set.
+func (r BackupRetention) IsEmpty() bool {
+	return r.Keep <= 0 && r.MaxAge <= 0 && r.MaxSize <= 0
+}
+
+// BackupsToPrune returns the backups, sorted newest first, exceeding the retention policies:
+// the ones after the first Keep, the ones older than MaxAge and the oldest ones making the
+// total size greater than MaxSize. The newest backup is never pruned.
+func BackupsToPrune(backups []BackupFile, retention BackupRetention, now time.Time) []BackupFile {
+	toPrune := []BackupFile{}
+	if retention.IsEmpty() {
+		return toPrune
+	}
+
+	var totalSize int64
+	isFull := false
+	for i, b := range backups {
+		if i > 0 && retention.MaxSize > 0 && totalSize+b.Size > retention.MaxSize {
+			isFull = true
+		}
+		switch {
+		case i == 0:
+		case isFull,
+			retention.Keep > 0 && i >= retention.Keep,
+			retention.MaxAge > 0 && now.Sub(b.ModTime) > retention.MaxAge:
+			toPrune = append(toPrune, b)
+			continue
+		}
+		totalSize += b.Size
+	}
+	return toPrune
+}
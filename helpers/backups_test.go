@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestGetAllBackups(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	backups, err := GetAllBackups(memFS, "backups")
+	is.NoErr(err)
+	is.Equal(0, len(backups))
+
+	now := time.Now()
+	is.NoErr(afero.WriteFile(memFS, "backups/site_old.tar.gz", []byte("old"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "backups/site_new.tar.gz", []byte("new"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "backups/notes.txt", []byte("notes"), 0644))
+	is.NoErr(memFS.Chtimes("backups/site_old.tar.gz", now.Add(-time.Hour), now.Add(-time.Hour)))
+
+	backups, err = GetAllBackups(memFS, "backups")
+	is.NoErr(err)
+	is.Equal(2, len(backups))
+	is.Equal("site_new.tar.gz", backups[0].Name)
+	is.Equal("site_old.tar.gz", backups[1].Name)
+}
+
+func TestBackupsToPrune(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	backups := []BackupFile{
+		{Name: "a", Size: 100, ModTime: now.Add(-1 * day)},
+		{Name: "b", Size: 100, ModTime: now.Add(-2 * day)},
+		{Name: "c", Size: 100, ModTime: now.Add(-10 * day)},
+		{Name: "d", Size: 10, ModTime: now.Add(-20 * day)},
+	}
+
+	tests := []struct {
+		name      string
+		retention BackupRetention
+		want      []string
+	}{
+		{"no policies", BackupRetention{}, []string{}},
+		{"keep", BackupRetention{Keep: 2}, []string{"c", "d"}},
+		{"max age", BackupRetention{MaxAge: 5 * day}, []string{"c", "d"}},
+		{"max size", BackupRetention{MaxSize: 250}, []string{"c", "d"}},
+		{"newest always kept", BackupRetention{MaxSize: 50, MaxAge: time.Hour}, []string{"b", "c", "d"}},
+		{"combined", BackupRetention{Keep: 3, MaxAge: 15 * day}, []string{"d"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			names := []string{}
+			for _, b := range BackupsToPrune(backups, tc.retention, now) {
+				names = append(names, b.Name)
+			}
+			is.Equal(tc.want, names)
+		})
+	}
+}
+
+func TestGetBackupEntries(t *testing.T) {
+	is := is.New(t)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range []string{"index.html", "posts/first.html"} {
+		is.NoErr(tarWriter.WriteHeader(&tar.Header{Name: name, Size: int64(len(name)), Mode: 0644, Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write([]byte(name))
+		is.NoErr(err)
+	}
+	is.NoErr(tarWriter.Close())
+	is.NoErr(gzipWriter.Close())
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "backups/site.tar.gz", buf.Bytes(), 0644))
+
+	entries, err := GetBackupEntries(memFS, "backups/site.tar.gz")
+	is.NoErr(err)
+	is.Equal(2, len(entries))
+	is.Equal("posts/first.html", entries[1].Name)
+	is.Equal(int64(16), entries[1].Size)
+
+	_, err = GetBackupEntries(memFS, "backups/missing.tar.gz")
+	is.True(err != nil)
+}
@@ -0,0 +1,305 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/common"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"gopkg.in/yaml.v3"
+)
+
+// ContentIndexFiles are the names of the content files, in order of precedence.
+var ContentIndexFiles = []string{"index.svx", "index.md", "index.svelte.md"}
+
+// frontMatterDateLayouts are the accepted layouts for the created_at and updated_at values.
+var frontMatterDateLayouts = []string{
+	"02-Jan-2006",
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02/01/2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+}
+
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// FrontMatterError is the error returned for a malformed front matter.
+// Line is relative to the content file, 0 if unknown.
+type FrontMatterError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *FrontMatterError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// ContentIndexError is the error returned when one or more content files have a malformed front matter.
+type ContentIndexError struct {
+	Errors []*FrontMatterError
+}
+
+func (e *ContentIndexError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		lines = append(lines, err.Error())
+	}
+	return fmt.Sprintf("malformed front matter in %d content file(s):\n  %s", len(e.Errors), strings.Join(lines, "\n  "))
+}
+
+// frontMatter is the struct the front matter values are decoded into.
+type frontMatter struct {
+	Layout     interface{} `yaml:"layout"`
+	Title      string      `yaml:"title"`
+	Author     string      `yaml:"author"`
+	Slug       string      `yaml:"slug"`
+	Headline   string      `yaml:"headline"`
+	Keywords   stringList  `yaml:"keywords"`
+	Tags       stringList  `yaml:"tags"`
+	Categories stringList  `yaml:"categories"`
+	Cover      string      `yaml:"cover"`
+	Draft      bool        `yaml:"draft"`
+	CreatedAt  string      `yaml:"created_at"`
+	UpdatedAt  string      `yaml:"updated_at"`
+}
+
+// stringList accepts both a list of strings and a comma separated string.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = nil
+		for _, s := range strings.Split(value.Value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				*l = append(*l, s)
+			}
+		}
+		return nil
+	}
+	var items []string
+	if err := value.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// BuildContentIndex parses the front matter of the content files for all the resources.
+// Contents without a content file are skipped. The index is returned along with a
+// *ContentIndexError listing the files with a malformed front matter, if any.
+func BuildContentIndex(fs afero.Fs, resources []string, path string) (*tpltypes.ContentIndex, error) {
+	index := &tpltypes.ContentIndex{
+		Resources: resources,
+		Entries:   make(map[string][]*tpltypes.ContentEntry),
+	}
+	if !common.DirExists(fs, path) {
+		return index, nil
+	}
+
+	indexErr := &ContentIndexError{}
+	contents := GetResourceContentMap(fs, resources, path)
+	for _, resource := range resources {
+		for _, name := range contents[resource] {
+			file := contentIndexFile(fs, filepath.Join(path, resource, name))
+			if file == "" {
+				continue
+			}
+			entry, err := ParseContentFile(fs, file)
+			if err != nil {
+				indexErr.Errors = append(indexErr.Errors, err)
+				continue
+			}
+			entry.Resource = resource
+			entry.Name = name
+			index.Entries[resource] = append(index.Entries[resource], entry)
+		}
+		sortContentEntries(index.Entries[resource])
+	}
+
+	if len(indexErr.Errors) > 0 {
+		return index, indexErr
+	}
+	return index, nil
+}
+
+// ParseContentFile returns the entry for the front matter of the content file.
+// Resource and Name are not set.
+func ParseContentFile(fs afero.Fs, file string) (*tpltypes.ContentEntry, *FrontMatterError) {
+	data, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, &FrontMatterError{File: file, Msg: err.Error()}
+	}
+	entry := &tpltypes.ContentEntry{Path: file, Params: map[string]interface{}{}}
+
+	raw, offset, ferr := extractFrontMatter(data)
+	if ferr != nil {
+		ferr.File = file
+		return nil, ferr
+	}
+	if raw == nil {
+		return entry, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, newYAMLError(file, offset, err)
+	}
+	if len(doc.Content) == 0 {
+		return entry, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &FrontMatterError{File: file, Line: root.Line + offset, Msg: "the front matter must be a mapping of keys and values"}
+	}
+
+	var fm frontMatter
+	if err := root.Decode(&fm); err != nil {
+		return nil, newYAMLError(file, offset, err)
+	}
+	if err := root.Decode(&entry.Params); err != nil {
+		return nil, newYAMLError(file, offset, err)
+	}
+
+	entry.Title = fm.Title
+	entry.Author = fm.Author
+	entry.Slug = fm.Slug
+	entry.Headline = fm.Headline
+	entry.Keywords = fm.Keywords
+	entry.Tags = fm.Tags
+	entry.Categories = fm.Categories
+	entry.Cover = fm.Cover
+	entry.Draft = fm.Draft
+	if layout, ok := fm.Layout.(string); ok {
+		entry.Layout = layout
+	}
+	for _, d := range []struct {
+		key   string
+		value string
+		dest  *time.Time
+	}{{"created_at", fm.CreatedAt, &entry.Created}, {"updated_at", fm.UpdatedAt, &entry.Updated}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := parseFrontMatterDate(d.value)
+		if err != nil {
+			return nil, &FrontMatterError{File: file, Line: keyLine(root, d.key) + offset, Msg: fmt.Sprintf("invalid date '%s' for %s", d.value, d.key)}
+		}
+		*d.dest = t
+	}
+	return entry, nil
+}
+
+//=============================================================================
+
+// contentIndexFile returns the path to the content file within the folder, empty if none.
+func contentIndexFile(fs afero.Fs, folder string) string {
+	for _, name := range ContentIndexFiles {
+		file := filepath.Join(folder, name)
+		if exists, _ := afero.Exists(fs, file); exists {
+			return file
+		}
+	}
+	return ""
+}
+
+// extractFrontMatter returns the front matter between the leading "---" delimiters and the
+// number of lines preceding it within the file. It returns nil if there is no front matter.
+func extractFrontMatter(data []byte) ([]byte, int, *FrontMatterError) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+
+	lineNo, start := 0, 0
+	var fm bytes.Buffer
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if start == 0 {
+			if line == "" {
+				continue
+			}
+			if line != "---" {
+				return nil, 0, nil
+			}
+			start = lineNo
+			continue
+		}
+		if line == "---" {
+			return fm.Bytes(), start, nil
+		}
+		fm.WriteString(scanner.Text())
+		fm.WriteByte('\n')
+	}
+	if start == 0 {
+		return nil, 0, nil
+	}
+	return nil, 0, &FrontMatterError{Line: start, Msg: "the front matter is not closed by a '---' line"}
+}
+
+// newYAMLError returns the error for the YAML error, with lines relative to the content file.
+func newYAMLError(file string, offset int, err error) *FrontMatterError {
+	line := 0
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	msg = yamlLineRegexp.ReplaceAllStringFunc(msg, func(m string) string {
+		n, _ := strconv.Atoi(yamlLineRegexp.FindStringSubmatch(m)[1])
+		if line == 0 {
+			line = n + offset
+		}
+		return "line " + strconv.Itoa(n+offset)
+	})
+	msg = strings.Join(strings.Fields(msg), " ")
+	msg = strings.TrimPrefix(msg, fmt.Sprintf("line %d: ", line))
+	return &FrontMatterError{File: file, Line: line, Msg: msg}
+}
+
+func keyLine(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i].Line
+		}
+	}
+	return 0
+}
+
+func parseFrontMatterDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range frontMatterDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format '%s'", value)
+}
+
+// sortContentEntries sorts by creation date, newest first, then by name.
+func sortContentEntries(entries []*tpltypes.ContentEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Created.Equal(entries[j].Created) {
+			return entries[i].Created.After(entries[j].Created)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
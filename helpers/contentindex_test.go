@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestBuildContentIndex(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "content/posts/first/index.svx", []byte(`---
+layout: false
+title: First Post
+author: Jane
+slug: first-post
+keywords: ['go', 'svelte']
+created_at: 02-Jan-2023
+updated_at: 2023-03-04
+draft: false
+custom: 42
+---
+
+Hello
+`), 0644))
+	is.NoErr(afero.WriteFile(memFS, "content/posts/second/index.md", []byte(`---
+title: "Second Post"
+tags: go, yaml
+created_at: 2023-05-06T10:00:00Z
+draft: true
+---
+`), 0644))
+	is.NoErr(afero.WriteFile(memFS, "content/posts/nofm/index.svelte.md", []byte("# No front matter\n"), 0644))
+	is.NoErr(memFS.MkdirAll("content/posts/empty", 0755))
+
+	index, err := BuildContentIndex(memFS, []string{"posts", "missing"}, "content")
+	is.NoErr(err)
+
+	entries := index.Get("posts")
+	is.Equal(3, len(entries))
+	is.Equal("second", entries[0].Name)
+	is.Equal("first", entries[1].Name)
+	is.Equal("nofm", entries[2].Name)
+
+	first := index.Find("posts", "first")
+	is.Equal("First Post", first.Title)
+	is.Equal("Jane", first.Author)
+	is.Equal("first-post", first.Slug)
+	is.Equal([]string{"go", "svelte"}, first.Keywords)
+	is.Equal(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), first.Created)
+	is.Equal(time.Date(2023, 3, 4, 0, 0, 0, 0, time.UTC), first.LastModified())
+	is.Equal(42, first.Params["custom"])
+	is.Equal("content/posts/first/index.svx", first.Path)
+
+	second := index.Find("posts", "second")
+	is.True(second.Draft)
+	is.Equal([]string{"go", "yaml"}, second.Tags)
+	is.Equal(second.Created, second.LastModified())
+
+	is.Equal(2, len(index.Published("posts")))
+	is.Equal(0, len(index.Get("missing")))
+}
+
+func TestBuildContentIndexErrors(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "content/posts/good/index.svx", []byte("---\ntitle: Good\n---\n"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "content/posts/syntax/index.svx", []byte("\n---\ntitle: Bad\nauthor: Jane: Doe\n---\n"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "content/posts/type/index.svx", []byte("---\ntitle: Bad\ndraft: maybe\n---\n"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "content/posts/date/index.svx", []byte("---\ntitle: Bad\ncreated_at: yesterday\n---\n"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "content/posts/open/index.svx", []byte("---\ntitle: Bad\n"), 0644))
+
+	index, err := BuildContentIndex(memFS, []string{"posts"}, "content")
+	var indexErr *ContentIndexError
+	is.True(errors.As(err, &indexErr))
+	is.Equal(4, len(indexErr.Errors))
+	is.Equal(1, len(index.Get("posts")))
+
+	lines := map[string]int{}
+	for _, e := range indexErr.Errors {
+		lines[e.File] = e.Line
+	}
+	is.Equal(1, lines["content/posts/open/index.svx"])
+	is.Equal(3, lines["content/posts/date/index.svx"])
+	is.Equal(3, lines["content/posts/type/index.svx"])
+	is.Equal(4, lines["content/posts/syntax/index.svx"])
+}
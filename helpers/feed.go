@@ -0,0 +1,185 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package helpers
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// DefaultFeedLimit is the number of items within a feed when not set.
+const DefaultFeedLimit = 20
+
+// websiteValueRegexp matches the "key: 'value'" lines with a string literal value.
+var websiteValueRegexp = regexp.MustCompile(`(?m)^\s*(\w+)\s*:\s*(?:'([^'\\]*)'|"([^"\\]*)")\s*,?\s*$`)
+
+// ReadWebsiteConfig returns the values set as plain string literals within the website config
+// file (config/website.js.ts), e.g. title, description and language. Values set by expressions
+// are skipped, as well as nested ones shadowed by a previous key. A missing file returns an empty map.
+func ReadWebsiteConfig(fs afero.Fs, file string) map[string]string {
+	values := make(map[string]string)
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return values
+	}
+	for _, m := range websiteValueRegexp.FindAllStringSubmatch(string(content), -1) {
+		if _, ok := values[m[1]]; ok {
+			continue
+		}
+		values[m[1]] = m[2] + m[3]
+	}
+	return values
+}
+
+// NewFeedData returns the feed for the published contents of all the resources, newest first.
+// Channel values are taken from the rss settings in sveltin.json, then from the website config.
+// A limit lower than 1 includes all the contents. Cover images found within the static folder
+// are added as enclosures.
+func NewFeedData(fs afero.Fs, settings *tpltypes.ProjectSettings, website map[string]string, index *tpltypes.ContentIndex, staticPath, feedFile string, limit int) *tpltypes.FeedData {
+	baseURL := strings.TrimRight(settings.BaseURL, "/")
+	rss := settings.RSS
+	feed := &tpltypes.FeedData{
+		ID:             FeedID(baseURL + "/"),
+		Title:          firstNotEmpty(rss.Title, website["title"], settings.Name),
+		Link:           baseURL + "/",
+		FeedURL:        baseURL + "/" + feedFile,
+		Description:    firstNotEmpty(rss.Description, website["description"], website["seoDescription"]),
+		Language:       firstNotEmpty(rss.Language, website["language"]),
+		Copyright:      firstNotEmpty(rss.Copyright, website["copyright"]),
+		ManagingEditor: rss.ManagingEditor,
+		Generator:      "Sveltin",
+		Items:          []*tpltypes.FeedItem{},
+	}
+	if feed.Description == "" {
+		feed.Description = feed.Title
+	}
+
+	entries := []*tpltypes.ContentEntry{}
+	if index != nil {
+		for _, resource := range index.Resources {
+			entries = append(entries, index.Published(resource)...)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Created.Equal(entries[j].Created) {
+			return entries[i].Created.After(entries[j].Created)
+		}
+		if entries[i].Resource != entries[j].Resource {
+			return entries[i].Resource < entries[j].Resource
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	for _, e := range entries {
+		slug := contentSlug(e)
+		item := &tpltypes.FeedItem{
+			ID:          FeedID(baseURL + "/" + e.Resource + "/" + slug),
+			Title:       firstNotEmpty(e.Title, e.Name),
+			Link:        baseURL + "/" + e.Resource + "/" + slug + "/",
+			Description: e.Headline,
+			Author:      e.Author,
+			Categories:  append(append(append([]string{}, e.Categories...), e.Tags...), e.Keywords...),
+			Published:   e.Created,
+			Updated:     e.LastModified(),
+		}
+		if e.Cover != "" {
+			item.Enclosure = newFeedEnclosure(fs, baseURL, staticPath, e)
+		}
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// NewResourceFeedData returns the feed for the published contents of the resource, written
+// to <resource>/<feedFile>. Title and description are taken from the rss resources settings
+// in sveltin.json, defaulting to ones derived from the aggregate feed.
+func NewResourceFeedData(fs afero.Fs, settings *tpltypes.ProjectSettings, website map[string]string, index *tpltypes.ContentIndex, resource, staticPath, feedFile string, limit int) *tpltypes.FeedData {
+	resourceIndex := &tpltypes.ContentIndex{
+		Resources: []string{resource},
+		Entries:   map[string][]*tpltypes.ContentEntry{resource: index.Get(resource)},
+	}
+	feed := NewFeedData(fs, settings, website, resourceIndex, staticPath, resource+"/"+feedFile, limit)
+
+	baseURL := strings.TrimRight(settings.BaseURL, "/")
+	label := utils.ToTitle(resource)
+	opts := settings.RSS.Resources[resource]
+	feed.ID = FeedID(baseURL + "/" + resource + "/")
+	feed.Link = baseURL + "/" + resource + "/"
+	feed.Description = firstNotEmpty(opts.Description, fmt.Sprintf("Latest %s from %s", label, feed.Title))
+	feed.Title = firstNotEmpty(opts.Title, feed.Title+" - "+label)
+	return feed
+}
+
+// FeedID returns a name based (version 5) UUID URN for the URL, e.g. urn:uuid:6ba7b811-9dad-51d1-80b4-00c04fd430c8.
+func FeedID(url string) string {
+	// the RFC 4122 namespace for URLs
+	namespace := []byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	h := sha1.New()
+	h.Write(namespace)
+	h.Write([]byte(url))
+	u := h.Sum(nil)[:16]
+	u[6] = (u[6] & 0x0f) | 0x50
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+//=============================================================================
+
+// newFeedEnclosure returns the enclosure for the content cover, stored within
+// the static folder as resources/<resource>/<slug>/<cover>.
+func newFeedEnclosure(fs afero.Fs, baseURL, staticPath string, e *tpltypes.ContentEntry) *tpltypes.FeedEnclosure {
+	if strings.HasPrefix(e.Cover, "http://") || strings.HasPrefix(e.Cover, "https://") {
+		return &tpltypes.FeedEnclosure{URL: e.Cover, Type: coverType(e.Cover)}
+	}
+	slug := contentSlug(e)
+	enclosure := &tpltypes.FeedEnclosure{
+		URL:  baseURL + "/resources/" + e.Resource + "/" + slug + "/" + e.Cover,
+		Type: coverType(e.Cover),
+	}
+	if info, err := fs.Stat(filepath.Join(staticPath, "resources", e.Resource, slug, e.Cover)); err == nil {
+		enclosure.Length = info.Size()
+	}
+	return enclosure
+}
+
+// contentSlug returns the slug the content page and its static folder are named after,
+// the content folder name when not set in the front matter.
+func contentSlug(e *tpltypes.ContentEntry) string {
+	return firstNotEmpty(e.Slug, e.Name)
+}
+
+func coverType(name string) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); t != "" {
+		return strings.Split(t, ";")[0]
+	}
+	return "application/octet-stream"
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+)
+
+func TestReadWebsiteConfig(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "config/website.js.ts", []byte(`const website: Sveltin.WebSite = {
+	name: name,
+	language: 'en-GB',
+	title: "My Website",
+	description: '',
+	copyright: '2023'.concat(" - ", name),
+	creator: {
+		name: 'Jane',
+		email: ''
+	}
+};
+`), 0644))
+
+	values := ReadWebsiteConfig(memFS, "config/website.js.ts")
+	is.Equal("en-GB", values["language"])
+	is.Equal("My Website", values["title"])
+	is.Equal("", values["description"])
+	is.Equal("Jane", values["name"])
+	_, ok := values["copyright"]
+	is.True(!ok)
+
+	is.Equal(0, len(ReadWebsiteConfig(memFS, "missing.js.ts")))
+}
+
+func TestNewFeedData(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "static/resources/posts/first/cover.png", []byte("png"), 0644))
+
+	day := func(d int) time.Time { return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC) }
+	index := &tpltypes.ContentIndex{
+		Resources: []string{"news", "posts"},
+		Entries: map[string][]*tpltypes.ContentEntry{
+			"posts": {
+				{Resource: "posts", Name: "draft", Title: "Draft", Draft: true, Created: day(9)},
+				{Resource: "posts", Name: "second", Title: "Second", Headline: "Second post", Created: day(5), Updated: day(6)},
+				{Resource: "posts", Name: "first", Slug: "first", Title: "First", Author: "Jane", Keywords: []string{"go"}, Cover: "cover.png", Created: day(1)},
+			},
+			"news": {
+				{Resource: "news", Name: "hello", Created: day(3)},
+			},
+		},
+	}
+	settings := &tpltypes.ProjectSettings{Name: "site", BaseURL: "https://example.com/"}
+	settings.RSS.Title = "Example"
+
+	feed := NewFeedData(memFS, settings, map[string]string{"title": "Ignored", "language": "en-GB"}, index, "static", "rss.xml", 0)
+	is.Equal("Example", feed.Title)
+	is.Equal("Example", feed.Description)
+	is.Equal("en-GB", feed.Language)
+	is.Equal("https://example.com/", feed.Link)
+	is.Equal("https://example.com/rss.xml", feed.FeedURL)
+	is.Equal(FeedID("https://example.com/"), feed.ID)
+	is.Equal(day(6), feed.Updated)
+
+	is.Equal(3, len(feed.Items))
+	is.Equal("https://example.com/posts/second/", feed.Items[0].Link)
+	is.Equal("Second post", feed.Items[0].Description)
+	is.Equal("hello", feed.Items[1].Title)
+	is.Equal("https://example.com/news/hello/", feed.Items[1].Link)
+
+	first := feed.Items[2]
+	is.Equal(FeedID("https://example.com/posts/first"), first.ID)
+	is.Equal("Jane", first.Author)
+	is.Equal([]string{"go"}, first.Categories)
+	is.Equal(&tpltypes.FeedEnclosure{URL: "https://example.com/resources/posts/first/cover.png", Length: 3, Type: "image/png"}, first.Enclosure)
+
+	feed = NewFeedData(memFS, settings, nil, index, "static", "rss.xml", 2)
+	is.Equal(2, len(feed.Items))
+	is.Equal("Second", feed.Items[0].Title)
+}
+
+func TestNewFeedDataCustomSlug(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "static/resources/posts/hello-world/cover.png", []byte("png"), 0644))
+
+	index := &tpltypes.ContentIndex{
+		Resources: []string{"posts"},
+		Entries: map[string][]*tpltypes.ContentEntry{
+			"posts": {{Resource: "posts", Name: "first-post", Slug: "hello-world", Title: "Hello", Cover: "cover.png"}},
+		},
+	}
+	settings := &tpltypes.ProjectSettings{Name: "site", BaseURL: "https://example.com"}
+
+	feed := NewFeedData(memFS, settings, nil, index, "static", "rss.xml", 0)
+	is.Equal(1, len(feed.Items))
+	item := feed.Items[0]
+	is.Equal(FeedID("https://example.com/posts/hello-world"), item.ID)
+	is.Equal("https://example.com/posts/hello-world/", item.Link)
+	is.Equal(&tpltypes.FeedEnclosure{URL: "https://example.com/resources/posts/hello-world/cover.png", Length: 3, Type: "image/png"}, item.Enclosure)
+}
+
+func TestFeedID(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal("urn:uuid:d276ae21-4807-5c28-bd49-05b37d06f624", FeedID("http://localhost/"))
+	is.Equal("urn:uuid:505a4ab2-cae0-510c-9529-75f3c717f1a5", FeedID("http://localhost/posts/hello"))
+}
+
+func TestNewResourceFeedData(t *testing.T) {
+	is := is.New(t)
+
+	index := &tpltypes.ContentIndex{
+		Resources: []string{"posts", "talks"},
+		Entries: map[string][]*tpltypes.ContentEntry{
+			"posts": {{Resource: "posts", Name: "hello", Title: "Hello"}},
+			"talks": {{Resource: "talks", Name: "intro", Title: "Intro"}},
+		},
+	}
+	settings := &tpltypes.ProjectSettings{Name: "site", BaseURL: "https://example.com"}
+	settings.RSS.Resources = map[string]tpltypes.RSSResourceData{
+		"talks": {Title: "My Talks", Description: "Slides and videos"},
+	}
+
+	feed := NewResourceFeedData(afero.NewMemMapFs(), settings, nil, index, "posts", "static", "rss.xml", 0)
+	is.Equal("site - Posts", feed.Title)
+	is.Equal("Latest Posts from site", feed.Description)
+	is.Equal("https://example.com/posts/", feed.Link)
+	is.Equal("https://example.com/posts/rss.xml", feed.FeedURL)
+	is.Equal(FeedID("https://example.com/posts/"), feed.ID)
+	is.Equal(1, len(feed.Items))
+	is.Equal("Hello", feed.Items[0].Title)
+
+	feed = NewResourceFeedData(afero.NewMemMapFs(), settings, nil, index, "talks", "static", "rss.xml", 0)
+	is.Equal("My Talks", feed.Title)
+	is.Equal("Slides and videos", feed.Description)
+	is.Equal("Intro", feed.Items[0].Title)
+}
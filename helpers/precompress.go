@@ -0,0 +1,174 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package helpers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/common"
+)
+
+// Extensions of the precompressed variants, written next to the original files.
+const (
+	GzipExt   string = ".gz"
+	BrotliExt string = ".br"
+)
+
+// DefaultPrecompressMinSize is the size, in bytes, below which files are not precompressed.
+const DefaultPrecompressMinSize int64 = 1024
+
+// PrecompressContentTypes is the map of the text file extensions to be precompressed and their content type.
+var PrecompressContentTypes = map[string]string{
+	".css":         "text/css",
+	".html":        "text/html",
+	".js":          "application/javascript",
+	".json":        "application/json",
+	".map":         "application/json",
+	".mjs":         "application/javascript",
+	".svg":         "image/svg+xml",
+	".txt":         "text/plain",
+	".webmanifest": "application/manifest+json",
+	".xml":         "application/xml",
+}
+
+// PrecompressedFile is the struct representing a file and its precompressed variants.
+// A variant size is 0 when it has not been written, being not smaller than the file.
+type PrecompressedFile struct {
+	Path       string
+	Size       int64
+	GzipSize   int64
+	BrotliSize int64
+}
+
+// Precompress writes the gzip and brotli variants for the text files within the folder
+// whose size is at least minSize bytes. Variants not smaller than the file are not written,
+// stale ones are removed. The output is deterministic, unchanged files produce the same variants.
+func Precompress(fs afero.Fs, folder string, minSize int64) ([]PrecompressedFile, error) {
+	files := []PrecompressedFile{}
+	if !common.DirExists(fs, folder) {
+		return files, nil
+	}
+
+	err := afero.Walk(fs, folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Size() < minSize {
+			return nil
+		}
+		if _, ok := PrecompressContentTypes[strings.ToLower(filepath.Ext(path))]; !ok {
+			return nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		file := PrecompressedFile{Path: path, Size: info.Size()}
+		if file.GzipSize, err = writeVariant(fs, path+GzipExt, data, gzipBytes); err != nil {
+			return err
+		}
+		if file.BrotliSize, err = writeVariant(fs, path+BrotliExt, data, brotliBytes); err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// writeVariant compresses the data and writes it to the file if smaller, returning its size.
+func writeVariant(fs afero.Fs, path string, data []byte, compress func([]byte) ([]byte, error)) (int64, error) {
+	compressed, err := compress(data)
+	if err != nil {
+		return 0, err
+	}
+	if len(compressed) >= len(data) {
+		if exists, _ := afero.Exists(fs, path); exists {
+			return 0, fs.Remove(path)
+		}
+		return 0, nil
+	}
+	if err := afero.WriteFile(fs, path, compressed, 0644); err != nil {
+		return 0, err
+	}
+	return int64(len(compressed)), nil
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	return closeWriter(w, &buf, data)
+}
+
+func brotliBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	return closeWriter(w, &buf, data)
+}
+
+func closeWriter(w io.WriteCloser, buf *bytes.Buffer, data []byte) ([]byte, error) {
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Markers delimiting the block written by sveltin within an .htaccess file.
+const (
+	htaccessBlockBegin string = "# BEGIN sveltin precompress"
+	htaccessBlockEnd   string = "# END sveltin precompress"
+)
+
+// WriteHtaccessBlock adds the block to the .htaccess file, creating it if missing.
+// A block previously written is replaced, the rest of the file is preserved.
+func WriteHtaccessBlock(fs afero.Fs, path string, block []byte) error {
+	content := ""
+	if exists, _ := afero.Exists(fs, path); exists {
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		content = string(data)
+	}
+
+	newBlock := htaccessBlockBegin + "\n" + strings.TrimSpace(string(block)) + "\n" + htaccessBlockEnd + "\n"
+	begin := strings.Index(content, htaccessBlockBegin)
+	end := strings.Index(content, htaccessBlockEnd)
+	switch {
+	case begin >= 0 && end > begin:
+		rest := strings.TrimPrefix(content[end+len(htaccessBlockEnd):], "\n")
+		content = content[:begin] + newBlock + rest
+	case content == "":
+		content = newBlock
+	default:
+		content = strings.TrimRight(content, "\n") + "\n\n" + newBlock
+	}
+	return afero.WriteFile(fs, path, []byte(content), 0644)
+}
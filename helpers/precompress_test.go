@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestPrecompress(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	page := strings.Repeat("<p>Hello Sveltin</p>\n", 200)
+	is.NoErr(afero.WriteFile(memFS, "build/index.html", []byte(page), 0644))
+	is.NoErr(afero.WriteFile(memFS, "build/_app/app.js", []byte(strings.Repeat("console.log(1);", 100)), 0644))
+	is.NoErr(afero.WriteFile(memFS, "build/small.css", []byte("body{}"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "build/logo.png", []byte(strings.Repeat("x", 4096)), 0644))
+
+	files, err := Precompress(memFS, "build", DefaultPrecompressMinSize)
+	is.NoErr(err)
+	is.Equal(2, len(files))
+	is.Equal("build/_app/app.js", files[0].Path)
+	is.Equal("build/index.html", files[1].Path)
+	is.True(files[1].GzipSize > 0 && files[1].GzipSize < files[1].Size)
+	is.True(files[1].BrotliSize > 0 && files[1].BrotliSize < files[1].Size)
+
+	gz, err := memFS.Open("build/index.html.gz")
+	is.NoErr(err)
+	gzReader, err := gzip.NewReader(gz)
+	is.NoErr(err)
+	data, err := io.ReadAll(gzReader)
+	is.NoErr(err)
+	is.Equal(page, string(data))
+
+	br, err := afero.ReadFile(memFS, "build/index.html.br")
+	is.NoErr(err)
+	data, err = io.ReadAll(brotli.NewReader(bytes.NewReader(br)))
+	is.NoErr(err)
+	is.Equal(page, string(data))
+
+	for _, path := range []string{"build/small.css.gz", "build/logo.png.gz", "build/index.html.gz.gz"} {
+		exists, _ := afero.Exists(memFS, path)
+		is.True(!exists)
+	}
+
+	// the variants are the same on the next run
+	before, err := afero.ReadFile(memFS, "build/index.html.gz")
+	is.NoErr(err)
+	_, err = Precompress(memFS, "build", DefaultPrecompressMinSize)
+	is.NoErr(err)
+	after, err := afero.ReadFile(memFS, "build/index.html.gz")
+	is.NoErr(err)
+	is.Equal(before, after)
+
+	files, err = Precompress(memFS, "missing", DefaultPrecompressMinSize)
+	is.NoErr(err)
+	is.Equal(0, len(files))
+}
+
+func TestWriteHtaccessBlock(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(WriteHtaccessBlock(memFS, "build/.htaccess", []byte("AddEncoding br .br\n")))
+	data, err := afero.ReadFile(memFS, "build/.htaccess")
+	is.NoErr(err)
+	is.Equal("# BEGIN sveltin precompress\nAddEncoding br .br\n# END sveltin precompress\n", string(data))
+
+	is.NoErr(afero.WriteFile(memFS, "build/.htaccess", []byte("Options -Indexes\n"), 0644))
+	is.NoErr(WriteHtaccessBlock(memFS, "build/.htaccess", []byte("AddEncoding br .br")))
+	is.NoErr(WriteHtaccessBlock(memFS, "build/.htaccess", []byte("AddEncoding gzip .gz")))
+	data, err = afero.ReadFile(memFS, "build/.htaccess")
+	is.NoErr(err)
+	is.Equal("Options -Indexes\n\n# BEGIN sveltin precompress\nAddEncoding gzip .gz\n# END sveltin precompress\n", string(data))
+}
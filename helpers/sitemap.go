@@ -0,0 +1,128 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package helpers
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/common"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+)
+
+// sitemapRouteFiles are the route files used to get the last modification time of a page.
+var sitemapRouteFiles = []string{"+page.svelte", "+page.svx", "+page.md"}
+
+// NewSitemapURLs returns the urls for the home page, the routes and the published contents
+// of the routes being resources. Draft contents are skipped. The last modification date of a
+// content is its updated_at value, the content file modification time otherwise; the one of a
+// resource is the latest among its contents. Changefreq and priority are overridden by the
+// sitemap resources and routes settings.
+func NewSitemapURLs(fs afero.Fs, settings *tpltypes.ProjectSettings, routes []string, index *tpltypes.ContentIndex, routesPath string) []*tpltypes.SitemapURL {
+	baseURL := strings.TrimRight(settings.BaseURL, "/")
+	urls := []*tpltypes.SitemapURL{
+		newSitemapURL(&settings.Sitemap, baseURL+"/", "", "", time.Time{}),
+	}
+
+	for _, route := range routes {
+		route = strings.Trim(filepath.ToSlash(route), "/")
+		if route == "" {
+			continue
+		}
+		resource := ""
+		if index != nil && common.Contains(index.Resources, route) {
+			resource = route
+		}
+
+		contentURLs := []*tpltypes.SitemapURL{}
+		var lastMod time.Time
+		for _, e := range index.Published(resource) {
+			modified := e.Updated
+			if modified.IsZero() {
+				modified = fileModTime(fs, e.Path)
+			}
+			if modified.After(lastMod) {
+				lastMod = modified
+			}
+			contentURLs = append(contentURLs, newSitemapURL(&settings.Sitemap, baseURL+"/"+route+"/"+e.Name+"/", resource, route+"/"+e.Name, modified))
+		}
+		if resource == "" {
+			lastMod = routeModTime(fs, routesPath, route)
+		}
+
+		urls = append(urls, newSitemapURL(&settings.Sitemap, baseURL+"/"+route+"/", resource, route, lastMod))
+		urls = append(urls, contentURLs...)
+	}
+	return urls
+}
+
+//=============================================================================
+
+// newSitemapURL returns the url with the default values, overridden by the resource ones and then by the route ones.
+func newSitemapURL(sitemap *tpltypes.SitemapData, loc, resource, route string, lastMod time.Time) *tpltypes.SitemapURL {
+	changeFreq, priority := sitemap.ChangeFreq, sitemap.Priority
+	for _, override := range []struct {
+		values map[string]tpltypes.SitemapOverrideData
+		key    string
+	}{{sitemap.Resources, resource}, {sitemap.Routes, route}} {
+		if override.key == "" {
+			continue
+		}
+		o, ok := override.values[override.key]
+		if !ok {
+			continue
+		}
+		if o.ChangeFreq != "" {
+			changeFreq = o.ChangeFreq
+		}
+		if o.Priority != nil {
+			priority = *o.Priority
+		}
+	}
+
+	return &tpltypes.SitemapURL{
+		Loc:        loc,
+		LastMod:    formatLastMod(lastMod),
+		ChangeFreq: changeFreq,
+		Priority:   strconv.FormatFloat(float64(priority), 'f', -1, 32),
+	}
+}
+
+// formatLastMod returns the date only when the time is midnight UTC, the full W3C datetime otherwise.
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if t.Location() == time.UTC && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+func routeModTime(fs afero.Fs, routesPath, route string) time.Time {
+	for _, name := range sitemapRouteFiles {
+		if t := fileModTime(fs, filepath.Join(routesPath, route, name)); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func fileModTime(fs afero.Fs, path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := fs.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime().Truncate(time.Second)
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/sveltin/internal/tpltypes"
+)
+
+func TestNewSitemapURLs(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	modTime := time.Date(2023, 2, 3, 10, 20, 30, 0, time.UTC)
+	is.NoErr(afero.WriteFile(memFS, "content/posts/second/index.svx", []byte("---\n---\n"), 0644))
+	is.NoErr(memFS.Chtimes("content/posts/second/index.svx", modTime, modTime))
+	is.NoErr(afero.WriteFile(memFS, "src/routes/about/+page.svelte", []byte("<h1>About</h1>"), 0644))
+	is.NoErr(memFS.Chtimes("src/routes/about/+page.svelte", modTime, modTime))
+
+	index := &tpltypes.ContentIndex{
+		Resources: []string{"posts", "talks"},
+		Entries: map[string][]*tpltypes.ContentEntry{
+			"posts": {
+				{Resource: "posts", Name: "draft", Draft: true, Updated: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+				{Resource: "posts", Name: "first", Updated: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+				{Resource: "posts", Name: "second", Path: "content/posts/second/index.svx"},
+			},
+		},
+	}
+	low, high := float32(0.3), float32(1)
+	settings := &tpltypes.ProjectSettings{BaseURL: "https://example.com/"}
+	settings.Sitemap = tpltypes.SitemapData{
+		ChangeFreq: "weekly",
+		Priority:   0.5,
+		Resources: map[string]tpltypes.SitemapOverrideData{
+			"posts": {ChangeFreq: "daily", Priority: &high},
+			"talks": {Priority: &low},
+		},
+		Routes: map[string]tpltypes.SitemapOverrideData{
+			"posts/first": {ChangeFreq: "monthly"},
+			"about":       {Priority: &low},
+		},
+	}
+
+	urls := NewSitemapURLs(memFS, settings, []string{"", "about", "/posts", "talks"}, index, "src/routes")
+	is.Equal([]*tpltypes.SitemapURL{
+		{Loc: "https://example.com/", ChangeFreq: "weekly", Priority: "0.5"},
+		{Loc: "https://example.com/about/", LastMod: "2023-02-03T10:20:30Z", ChangeFreq: "weekly", Priority: "0.3"},
+		{Loc: "https://example.com/posts/", LastMod: "2023-02-03T10:20:30Z", ChangeFreq: "daily", Priority: "1"},
+		{Loc: "https://example.com/posts/first/", LastMod: "2023-01-02", ChangeFreq: "monthly", Priority: "1"},
+		{Loc: "https://example.com/posts/second/", LastMod: "2023-02-03T10:20:30Z", ChangeFreq: "daily", Priority: "1"},
+		{Loc: "https://example.com/talks/", ChangeFreq: "weekly", Priority: "0.3"},
+	}, urls)
+}
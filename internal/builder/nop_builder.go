@@ -8,16 +8,20 @@
 package builder
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/sveltinio/sveltin/config"
 	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
 	"github.com/sveltinio/sveltin/utils"
 )
 
-// NoPContentBuilder represents the builder for the no-page artefacts (sitemap and rss).
+// NoPContentBuilder represents the builder for the no-page artefacts (sitemap and feeds).
 type NoPContentBuilder struct {
 	ContentType       string
 	EmbeddedResources map[string]string
@@ -46,6 +50,12 @@ func (b *NoPContentBuilder) setPathToTplFile() error {
 	case "rss":
 		b.PathToTplFile = b.EmbeddedResources["rss_static"]
 		return nil
+	case "atom":
+		b.PathToTplFile = b.EmbeddedResources["atom_static"]
+		return nil
+	case "jsonfeed":
+		b.PathToTplFile = b.EmbeddedResources["jsonfeed_static"]
+		return nil
 	case "sitemap":
 		b.PathToTplFile = b.EmbeddedResources["sitemap_static"]
 		return nil
@@ -74,6 +84,24 @@ func (b *NoPContentBuilder) setFuncs() {
 		"Trimmed": func(txt string) string {
 			return utils.Trimmed(txt)
 		},
+		"XMLEscape": func(txt string) string {
+			var buf bytes.Buffer
+			_ = xml.EscapeText(&buf, []byte(txt))
+			return buf.String()
+		},
+		"RFC1123Z": func(t time.Time) string {
+			return t.Format(time.RFC1123Z)
+		},
+		"Now": func() time.Time {
+			return time.Now().UTC()
+		},
+		"RFC3339": func(t time.Time) string {
+			return t.Format(time.RFC3339)
+		},
+		"JSONString": func(txt string) string {
+			b, _ := json.Marshal(txt)
+			return string(b)
+		},
 	}
 }
 
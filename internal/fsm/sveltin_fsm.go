@@ -88,22 +88,38 @@ func (s *SveltinFSManager) NewPublicPageFile(pageData *tpltypes.PageData, projec
 	}
 }
 
-// NewNoPageFile returns a pointer to a 'no-public page' File.
-func (s *SveltinFSManager) NewNoPageFile(name string, data *tpltypes.ProjectSettings, resources []string, contents map[string][]string) *composer.File {
+// NewSitemapFile returns a pointer to a 'no-public page' File for the sitemap.
+func (s *SveltinFSManager) NewSitemapFile(data *tpltypes.ProjectSettings, urls []*tpltypes.SitemapURL, index *tpltypes.ContentIndex) *composer.File {
 	return &composer.File{
-		Name:       name + ".xml",
-		TemplateID: name,
+		Name:       "sitemap.xml",
+		TemplateID: "sitemap",
+		TemplateData: &config.TemplateData{
+			NoPage: &tpltypes.NoPageData{
+				Data: data,
+				URLs: urls,
+			},
+			ContentIndex: index,
+		},
+	}
+}
+
+// NewFeedFile returns a pointer to a 'no-public page' File for a feed, templateID is one of rss, atom and jsonfeed.
+func (s *SveltinFSManager) NewFeedFile(templateID string, filename string, data *tpltypes.ProjectSettings, feed *tpltypes.FeedData, index *tpltypes.ContentIndex) *composer.File {
+	return &composer.File{
+		Name:       filename,
+		TemplateID: templateID,
 		TemplateData: &config.TemplateData{
 			NoPage: &tpltypes.NoPageData{
-				Data:  data,
-				Items: helpers.NewNoPageItems(resources, contents),
+				Data: data,
+				Feed: feed,
 			},
+			ContentIndex: index,
 		},
 	}
 }
 
 // NewMenuFile returns a pointer to a 'no-public page' File.
-func (s *SveltinFSManager) NewMenuFile(name string, resources []string, contents map[string][]string, withContentFlag bool) *composer.File {
+func (s *SveltinFSManager) NewMenuFile(name string, resources []string, contents map[string][]string, index *tpltypes.ContentIndex, withContentFlag bool) *composer.File {
 	return &composer.File{
 		Name:       name + ".js.ts",
 		TemplateID: name,
@@ -112,6 +128,7 @@ func (s *SveltinFSManager) NewMenuFile(name string, resources []string, contents
 				Items:       helpers.NewMenuItems(resources, contents),
 				WithContent: withContentFlag,
 			},
+			ContentIndex: index,
 		},
 	}
 }
@@ -0,0 +1,146 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/yinlog"
+)
+
+// fetchFunc retrieves the content of a file stored on the remote server.
+type fetchFunc func(file string) ([]byte, error)
+
+// isDeployStateFile returns true for the files written by the deploy itself on the remote folder.
+// They describe the remote state at the time of the backup only, so they are never archived nor restored:
+// a restored lock would keep the remote locked and a restored manifest would not match the next deploy.
+func isDeployStateFile(name string) bool {
+	return name == LockFilename || name == ManifestFilename
+}
+
+// backupFiles returns the remote files to archive, the deploy state files are left out.
+func backupFiles(remoteFiles []string) []string {
+	files := make([]string, 0, len(remoteFiles))
+	for _, f := range remoteFiles {
+		if !isDeployStateFile(f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func createTarball(logger *yinlog.Logger, plain bool, appFs afero.Fs, tarballFilePath string, filePaths []string, fetch fetchFunc, dryRun bool) error {
+	logger.Info("Creating the backup archive...")
+	// In-memory file system
+	memFs := afero.NewMemMapFs()
+	// Create a new archive file
+	file, err := appFs.Create(tarballFilePath)
+	if err != nil {
+		return fmt.Errorf("could not create tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	pbConfig := &progressbar.Config{
+		Items:          filePaths,
+		OnCompletesMsg: fmt.Sprintf("Backup done! Saved as: %s", tarballFilePath),
+		OnProgressCmd: func(path string) tea.Cmd {
+			return createTarballTeaCmd(memFs, tarWriter, path, fetch, dryRun)
+		},
+	}
+
+	return runSteps(logger, plain, pbConfig)
+}
+
+func addToTarWriter(memFs afero.Fs, filePath string, tarWriter *tar.Writer) error {
+	file, err := memFs.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open file '%s', got error '%s'", filePath, err.Error())
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not get stat for file '%s', got error '%s'", filePath, err.Error())
+	}
+
+	header := &tar.Header{
+		Name:    filePath,
+		Size:    stat.Size(),
+		Mode:    int64(stat.Mode()),
+		ModTime: stat.ModTime(),
+	}
+
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return fmt.Errorf("could not write header for file '%s', got error '%s'", filePath, err.Error())
+	}
+
+	_, err = io.Copy(tarWriter, file)
+	if err != nil {
+		return fmt.Errorf("could not copy the file '%s' data to the tarball, got error '%s'", filePath, err.Error())
+	}
+
+	return nil
+}
+
+// extractTarball reads the tar archive into an in-memory file system and
+// returns it together with the sorted list of the archived files, the deploy state files excluded.
+func extractTarball(appFs afero.Fs, tarballFilePath string) (afero.Fs, []string, error) {
+	file, err := appFs.Open(tarballFilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+	}
+	defer gzipReader.Close()
+
+	memFs := afero.NewMemMapFs()
+	files := []string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+		}
+		// archives created by older releases may contain the deploy state files
+		if header.Typeflag != tar.TypeReg || isDeployStateFile(header.Name) {
+			continue
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not extract file '%s', got error '%s'", header.Name, err.Error())
+		}
+		if err := afero.WriteFile(memFs, header.Name, data, 0644); err != nil {
+			return nil, nil, err
+		}
+		files = append(files, header.Name)
+	}
+	sort.Strings(files)
+	return memFs, files, nil
+}
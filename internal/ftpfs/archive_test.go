@@ -0,0 +1,97 @@
+package ftpfs
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestExtractTarball(t *testing.T) {
+	is := is.New(t)
+
+	appFs := afero.NewMemMapFs()
+	memFs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFs, "index.html", []byte("<html></html>"), 0644))
+	is.NoErr(afero.WriteFile(memFs, "posts/first/index.html", []byte("first"), 0644))
+
+	f, err := appFs.Create("backup.tar.gz")
+	is.NoErr(err)
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	is.NoErr(addToTarWriter(memFs, "posts/first/index.html", tarWriter))
+	is.NoErr(addToTarWriter(memFs, "index.html", tarWriter))
+	is.NoErr(tarWriter.Close())
+	is.NoErr(gzipWriter.Close())
+	is.NoErr(f.Close())
+
+	extracted, files, err := extractTarball(appFs, "backup.tar.gz")
+	is.NoErr(err)
+	is.Equal([]string{"index.html", "posts/first/index.html"}, files)
+
+	data, err := afero.ReadFile(extracted, "posts/first/index.html")
+	is.NoErr(err)
+	is.Equal("first", string(data))
+
+	_, _, err = extractTarball(appFs, "missing.tar.gz")
+	is.True(err != nil)
+}
+
+func TestRestoreHoldingLock(t *testing.T) {
+	is := is.New(t)
+
+	// the backup taken by a previous deploy while holding its lock
+	old := NewLock("production")
+	oldLock, err := old.Bytes()
+	is.NoErr(err)
+	memFs := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFs, "index.html", []byte("<h1>Old</h1>"), 0644))
+	is.NoErr(afero.WriteFile(memFs, LockFilename, oldLock, 0644))
+	is.NoErr(afero.WriteFile(memFs, ManifestFilename, []byte("{}"), 0644))
+
+	appFs := afero.NewMemMapFs()
+	f, err := appFs.Create("backup.tar.gz")
+	is.NoErr(err)
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range []string{"index.html", LockFilename, ManifestFilename} {
+		is.NoErr(addToTarWriter(memFs, name, tarWriter))
+	}
+	is.NoErr(tarWriter.Close())
+	is.NoErr(gzipWriter.Close())
+	is.NoErr(f.Close())
+
+	conn := newMemServer("/www")
+	is.NoErr(conn.WriteFile("index.html", []byte("<h1>New</h1>"), false))
+	is.NoErr(conn.WriteFile(ManifestFilename, []byte("{}"), false))
+	lock := NewLock("production")
+	is.NoErr(AcquireLockAction(conn, lock, false, nil, false).Run())
+
+	is.NoErr(RestoreAction(conn, appFs, "backup.tar.gz", nil, false).Run())
+	data, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal("<h1>Old</h1>", string(data))
+	// the lock is still the one of the rollback
+	data, err = conn.ReadFile(LockFilename)
+	is.NoErr(err)
+	current, err := ParseLock(data)
+	is.NoErr(err)
+	is.Equal(lock.ID, current.ID)
+	// the manifest is not restored
+	_, err = conn.ReadFile(ManifestFilename)
+	is.True(err != nil)
+
+	// the remote is unlocked once the rollback is done
+	is.NoErr(ReleaseLockAction(conn, lock, false).Run())
+	_, err = conn.ReadFile(LockFilename)
+	is.True(err != nil)
+}
+
+func TestBackupFiles(t *testing.T) {
+	is := is.New(t)
+
+	files := backupFiles([]string{"index.html", LockFilename, ManifestFilename, "posts/" + ManifestFilename})
+	is.Equal([]string{"index.html", "posts/" + ManifestFilename}, files)
+}
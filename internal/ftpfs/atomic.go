@@ -0,0 +1,26 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import "strings"
+
+// Suffixes for the sibling folders used by the atomic deploy.
+const (
+	NextFolderSuffix = ".sveltin-next"
+	PrevFolderSuffix = ".sveltin-prev"
+)
+
+// NextFolder returns the sibling folder where the new content is uploaded to.
+func NextFolder(liveFolder string) string {
+	return strings.TrimSuffix(liveFolder, "/") + NextFolderSuffix
+}
+
+// PrevFolder returns the sibling folder where the live content is moved to on swap.
+func PrevFolder(liveFolder string) string {
+	return strings.TrimSuffix(liveFolder, "/") + PrevFolderSuffix
+}
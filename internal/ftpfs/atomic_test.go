@@ -0,0 +1,52 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestAtomicDeploy(t *testing.T) {
+	is := is.New(t)
+
+	conn := newMemServer("/www")
+	is.NoErr(conn.WriteFile("index.html", []byte("old"), false))
+	is.NoErr(conn.WriteFile(".htaccess", []byte("rules"), false))
+	is.NoErr(conn.WriteFile("uploads/photo.jpg", []byte("photo"), false))
+	keep, err := ParseIgnoreRules(IgnoreFilename, []string{"uploads/"})
+	is.NoErr(err)
+
+	is.NoErr(PrepareAtomicAction(conn, keep.Merge(ExcludeRules([]string{".htaccess"})), false).Run())
+	is.Equal("/www.sveltin-next", conn.RootFolder())
+	is.NoErr(conn.WriteFile("index.html", []byte("new"), false))
+	is.NoErr(SwapFoldersAction(conn, "/www", false).Run())
+
+	is.Equal("/www", conn.RootFolder())
+	data, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal("new", string(data))
+	is.Equal([]string{".htaccess", "index.html", "uploads/photo.jpg"}, conn.files("/www"))
+	is.Equal([]string{".htaccess", "index.html", "uploads/photo.jpg"}, conn.files("/www.sveltin-prev"))
+	exists, _ := conn.Exists("/www.sveltin-next")
+	is.True(!exists)
+}
+
+func TestAtomicDeployFailure(t *testing.T) {
+	is := is.New(t)
+
+	conn := newMemServer("/www")
+	conn.failRename = true
+	is.NoErr(conn.WriteFile("index.html", []byte("old"), false))
+
+	is.NoErr(PrepareAtomicAction(conn, nil, false).Run())
+	is.NoErr(conn.WriteFile("index.html", []byte("new"), false))
+	is.True(SwapFoldersAction(conn, "/www", false).Run() != nil)
+	is.NoErr(CleanupAtomicAction(conn, "/www", false).Run())
+
+	// the live folder is restored and the staging one removed
+	data, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal("old", string(data))
+	exists, _ := conn.Exists("/www.sveltin-next")
+	is.True(!exists)
+}
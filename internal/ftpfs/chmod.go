@@ -0,0 +1,169 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrChmodNotSupported is returned when the remote server cannot change the permissions of the remote files.
+var ErrChmodNotSupported = errors.New("the remote server does not support changing the file permissions")
+
+// Chmoder is implemented by the remote servers able to change the permissions of the remote files.
+type Chmoder interface {
+	// Chmod sets the permissions of the file or folder, relative to the remote folder.
+	// It returns ErrChmodNotSupported when the remote server rejects the command itself.
+	Chmod(name string, mode os.FileMode) error
+}
+
+// ChmodRule is the struct representing the permissions set on the remote paths matching a pattern.
+type ChmodRule struct {
+	Source  string
+	Pattern string
+	Mode    os.FileMode
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// String returns the rule as "<source>:<pattern>=<mode>".
+func (r *ChmodRule) String() string {
+	return fmt.Sprintf("%s:%s=%04o", r.Source, r.Pattern, r.Mode)
+}
+
+// ChmodRules is the ordered list of rules setting the permissions of the remote paths.
+// The last matching rule wins.
+type ChmodRules struct {
+	rules []*ChmodRule
+}
+
+// ParseChmodRules returns the rules for the "<pattern>=<mode>" values, e.g. "*=0644" and "*/=0755".
+// Patterns follow the .sveltinignore syntax: a trailing / matches folders only, patterns without
+// a slash match at any level and ** matches any number of folders. Modes are octal.
+func ParseChmodRules(source string, values []string) (*ChmodRules, error) {
+	r := &ChmodRules{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		idx := strings.LastIndex(value, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid chmod rule '%s' (%s), expected <pattern>=<mode>, e.g. *.php=0600", value, source)
+		}
+		pattern, modeStr := strings.TrimSpace(value[:idx]), strings.TrimSpace(value[idx+1:])
+		mode, err := strconv.ParseUint(modeStr, 8, 32)
+		if err != nil || mode > 0777 {
+			return nil, fmt.Errorf("invalid chmod rule '%s' (%s), '%s' is not an octal mode like 0644", value, source, modeStr)
+		}
+
+		rule := &ChmodRule{Source: source, Pattern: pattern, Mode: os.FileMode(mode)}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid chmod rule '%s' (%s), the pattern is empty", value, source)
+		}
+		if rule.re, err = compileIgnorePattern(pattern, anchored); err != nil {
+			return nil, fmt.Errorf("invalid chmod rule '%s' (%s), got error '%s'", value, source, err.Error())
+		}
+		r.rules = append(r.rules, rule)
+	}
+	return r, nil
+}
+
+// Merge returns the rules followed by the other ones, which take precedence.
+func (r *ChmodRules) Merge(other *ChmodRules) *ChmodRules {
+	merged := &ChmodRules{}
+	if r != nil {
+		merged.rules = append(merged.rules, r.rules...)
+	}
+	if other != nil {
+		merged.rules = append(merged.rules, other.rules...)
+	}
+	return merged
+}
+
+// IsEmpty returns true if there are no rules.
+func (r *ChmodRules) IsEmpty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+// Match returns the last rule matching the path, relative to the remote folder, or nil if none.
+func (r *ChmodRules) Match(name string, isDir bool) *ChmodRule {
+	if r.IsEmpty() {
+		return nil
+	}
+	name = strings.Trim(filepath.ToSlash(name), "/")
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		rule := r.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(name) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// ChmodChange is the struct representing the permissions set, or to be set on dry run, on a remote path.
+// Err is set when the remote server failed to change them.
+type ChmodChange struct {
+	Path string
+	Mode os.FileMode
+	Rule *ChmodRule
+	Err  error
+}
+
+// chmod sets the permissions of the folders and files, relative to the remote folder, matching the rules.
+// It stops with ErrChmodNotSupported as soon as the remote server does not support it, failures on
+// single paths are reported by the changes.
+func chmod(server RemoteServer, rules *ChmodRules, dirs, files []string, dryRun bool) ([]ChmodChange, error) {
+	changes := []ChmodChange{}
+	if rules.IsEmpty() {
+		return changes, nil
+	}
+	for _, group := range []struct {
+		paths []string
+		isDir bool
+	}{{dirs, true}, {files, false}} {
+		paths := append([]string{}, group.paths...)
+		sort.Strings(paths)
+		for _, p := range paths {
+			if rule := rules.Match(p, group.isDir); rule != nil {
+				changes = append(changes, ChmodChange{Path: filepath.ToSlash(p), Mode: rule.Mode, Rule: rule})
+			}
+		}
+	}
+	if dryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	chmoder, ok := server.(Chmoder)
+	if !ok {
+		return nil, ErrChmodNotSupported
+	}
+	for i := range changes {
+		err := chmoder.Chmod(changes[i].Path, changes[i].Mode)
+		if errors.Is(err, ErrChmodNotSupported) {
+			return nil, err
+		}
+		changes[i].Err = err
+	}
+	return changes, nil
+}
@@ -0,0 +1,95 @@
+package ftpfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// chmodServer is a memServer able to change the permissions, unless unsupported is set.
+type chmodServer struct {
+	*memServer
+	unsupported bool
+}
+
+func (s *chmodServer) Chmod(name string, mode os.FileMode) error {
+	if s.unsupported {
+		return ErrChmodNotSupported
+	}
+	return s.fs.Chmod(path.Join(s.serverFolder, name), mode)
+}
+
+func TestChmodRules(t *testing.T) {
+	is := is.New(t)
+
+	rules, err := ParseChmodRules("sveltin.json", []string{"*=0644", "*/=0755", "cgi-bin/*.php=0600"})
+	is.NoErr(err)
+	is.Equal(os.FileMode(0644), rules.Match("index.html", false).Mode)
+	is.Equal(os.FileMode(0644), rules.Match("_app/immutable/app.js", false).Mode)
+	is.Equal(os.FileMode(0755), rules.Match("_app/immutable", true).Mode)
+	is.Equal(os.FileMode(0600), rules.Match("cgi-bin/form.php", false).Mode)
+	is.Equal("sveltin.json:cgi-bin/*.php=0600", rules.Match("cgi-bin/form.php", false).String())
+
+	// the last rule wins
+	rules = rules.Merge(&ChmodRules{})
+	flagRules, err := ParseChmodRules("--chmod", []string{"*.html=0640"})
+	is.NoErr(err)
+	rules = rules.Merge(flagRules)
+	is.Equal(os.FileMode(0640), rules.Match("about/index.html", false).Mode)
+
+	for _, value := range []string{"*.php", "=0644", "*=0999", "*=rw", "*=01777"} {
+		_, err := ParseChmodRules("--chmod", []string{value})
+		is.True(err != nil)
+	}
+
+	none, err := ParseChmodRules("--chmod", []string{""})
+	is.NoErr(err)
+	is.True(none.IsEmpty())
+	is.True(none.Match("index.html", false) == nil)
+}
+
+func TestChmodAction(t *testing.T) {
+	is := is.New(t)
+
+	server := &chmodServer{memServer: newMemServer("/www")}
+	is.NoErr(server.MakeDirs([]string{"_app"}, false))
+	is.NoErr(server.WriteFile("index.html", []byte("<h1>Hello</h1>"), false))
+	is.NoErr(server.WriteFile("_app/app.js", []byte("app"), false))
+
+	rules, err := ParseChmodRules("--chmod", []string{"*=0640", "*/=0750"})
+	is.NoErr(err)
+
+	// dry run reports what would change
+	var changes []ChmodChange
+	is.NoErr(ChmodAction(server, rules, []string{"_app"}, []string{"index.html", "_app/app.js"}, &changes, true).Run())
+	is.Equal(3, len(changes))
+	is.Equal("_app", changes[0].Path)
+	is.Equal(os.FileMode(0750), changes[0].Mode)
+	is.Equal("_app/app.js", changes[1].Path)
+	info, err := server.fs.Stat("/www/index.html")
+	is.NoErr(err)
+	is.True(info.Mode().Perm() != 0640)
+
+	is.NoErr(ChmodAction(server, rules, []string{"_app"}, []string{"index.html", "_app/app.js"}, &changes, false).Run())
+	is.Equal(3, len(changes))
+	info, err = server.fs.Stat("/www/index.html")
+	is.NoErr(err)
+	is.Equal(os.FileMode(0640), info.Mode().Perm())
+	info, err = server.fs.Stat("/www/_app")
+	is.NoErr(err)
+	is.Equal(os.FileMode(0750), info.Mode().Perm())
+
+	// servers not supporting it stop at the first path
+	server.unsupported = true
+	err = ChmodAction(server, rules, nil, []string{"index.html"}, &changes, false).Run()
+	is.True(errors.Is(err, ErrChmodNotSupported))
+	err = ChmodAction(newMemServer("/www"), rules, nil, []string{"index.html"}, &changes, false).Run()
+	is.True(errors.Is(err, ErrChmodNotSupported))
+
+	// nothing to do without rules
+	is.NoErr(ChmodAction(newMemServer("/www"), nil, nil, []string{"index.html"}, &changes, false).Run())
+	is.Equal(0, len(changes))
+}
@@ -22,7 +22,7 @@ func (c *Client) Run() error {
 }
 
 // DialAction creates and configures the concrete dial command.
-func DialAction(conn *FTPServerConnection) *Client {
+func DialAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &DialCommand{
 			Server: conn,
@@ -31,7 +31,7 @@ func DialAction(conn *FTPServerConnection) *Client {
 }
 
 // LoginAction creates and configures the concrete login command.
-func LoginAction(conn *FTPServerConnection) *Client {
+func LoginAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &LoginCommand{
 			Server: conn,
@@ -40,7 +40,7 @@ func LoginAction(conn *FTPServerConnection) *Client {
 }
 
 // LogoutAction creates and configures the concrete logout command.
-func LogoutAction(conn *FTPServerConnection) *Client {
+func LogoutAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &LogoutCommand{
 			Server: conn,
@@ -49,7 +49,7 @@ func LogoutAction(conn *FTPServerConnection) *Client {
 }
 
 // IdleAction creates and configures the concrete no-operation(idle) command.
-func IdleAction(conn *FTPServerConnection) *Client {
+func IdleAction(conn RemoteServer) *Client {
 	return &Client{
 		Command: &IdleCommand{
 			Server: conn,
@@ -58,7 +58,7 @@ func IdleAction(conn *FTPServerConnection) *Client {
 }
 
 // MakeDirsAction creates and configures the concrete dial command.
-func MakeDirsAction(conn *FTPServerConnection, dirs []string, dryRun bool) *Client {
+func MakeDirsAction(conn RemoteServer, dirs []string, dryRun bool) *Client {
 	return &Client{
 		Command: &MakeDirsCommand{
 			Server: conn,
@@ -69,7 +69,7 @@ func MakeDirsAction(conn *FTPServerConnection, dirs []string, dryRun bool) *Clie
 }
 
 // UploadAction creates and configures the concrete upload command.
-func UploadAction(conn *FTPServerConnection, appFs afero.Fs, localDirname string, files []string, replaceBasePath, dryRun bool) *Client {
+func UploadAction(conn RemoteServer, appFs afero.Fs, localDirname string, files []string, replaceBasePath, dryRun bool) *Client {
 	return &Client{
 		Command: &UploadCommand{
 			Server:          conn,
@@ -83,18 +83,18 @@ func UploadAction(conn *FTPServerConnection, appFs afero.Fs, localDirname string
 }
 
 // DeleteAllAction creates and configures the concrete delete all command.
-func DeleteAllAction(conn *FTPServerConnection, exludeList []string, dryRun bool) *Client {
+func DeleteAllAction(conn RemoteServer, keep *IgnoreRules, dryRun bool) *Client {
 	return &Client{
 		Command: &DeleteAllCommand{
-			Server:      conn,
-			ExcludeList: exludeList,
-			DryRun:      dryRun,
+			Server: conn,
+			Keep:   keep,
+			DryRun: dryRun,
 		},
 	}
 }
 
 // BackupAction creates and configures the concrete backup command.
-func BackupAction(conn *FTPServerConnection, appFs afero.Fs, name string, dryRun bool) *Client {
+func BackupAction(conn RemoteServer, appFs afero.Fs, name string, dryRun bool) *Client {
 	return &Client{
 		Command: &BackupCommand{
 			Server: conn,
@@ -104,3 +104,156 @@ func BackupAction(conn *FTPServerConnection, appFs afero.Fs, name string, dryRun
 		},
 	}
 }
+
+// ReadManifestAction creates and configures the concrete read manifest command.
+func ReadManifestAction(conn RemoteServer, manifest *Manifest) *Client {
+	return &Client{
+		Command: &ReadManifestCommand{
+			Server:   conn,
+			Manifest: manifest,
+		},
+	}
+}
+
+// WriteManifestAction creates and configures the concrete write manifest command.
+func WriteManifestAction(conn RemoteServer, manifest Manifest, dryRun bool) *Client {
+	return &Client{
+		Command: &WriteManifestCommand{
+			Server:   conn,
+			Manifest: manifest,
+			DryRun:   dryRun,
+		},
+	}
+}
+
+// DeleteFilesAction creates and configures the concrete delete files command.
+func DeleteFilesAction(conn RemoteServer, files []string, dryRun bool) *Client {
+	return &Client{
+		Command: &DeleteFilesCommand{
+			Server: conn,
+			Files:  files,
+			DryRun: dryRun,
+		},
+	}
+}
+
+// RestoreAction creates and configures the concrete restore command.
+func RestoreAction(conn RemoteServer, appFs afero.Fs, tarball string, keep *IgnoreRules, dryRun bool) *Client {
+	return &Client{
+		Command: &RestoreCommand{
+			Server:  conn,
+			AppFs:   appFs,
+			Tarball: tarball,
+			Keep:    keep,
+			DryRun:  dryRun,
+		},
+	}
+}
+
+// PrepareAtomicAction creates and configures the concrete prepare atomic deploy command.
+func PrepareAtomicAction(conn RemoteServer, keep *IgnoreRules, dryRun bool) *Client {
+	return &Client{
+		Command: &PrepareAtomicCommand{
+			Server: conn,
+			Keep:   keep,
+			DryRun: dryRun,
+		},
+	}
+}
+
+// SwapFoldersAction creates and configures the concrete swap folders command.
+func SwapFoldersAction(conn RemoteServer, liveFolder string, dryRun bool) *Client {
+	return &Client{
+		Command: &SwapFoldersCommand{
+			Server:     conn,
+			LiveFolder: liveFolder,
+			DryRun:     dryRun,
+		},
+	}
+}
+
+// CleanupAtomicAction creates and configures the concrete cleanup atomic deploy command.
+func CleanupAtomicAction(conn RemoteServer, liveFolder string, dryRun bool) *Client {
+	return &Client{
+		Command: &CleanupAtomicCommand{
+			Server:     conn,
+			LiveFolder: liveFolder,
+			DryRun:     dryRun,
+		},
+	}
+}
+
+// VerifyAction creates and configures the concrete verify command.
+// The files to be compared are keyed by their path on the remote folder.
+func VerifyAction(conn RemoteServer, appFs afero.Fs, files map[string]string, keep *IgnoreRules, result *VerifyResult) *Client {
+	return &Client{
+		Command: &VerifyCommand{
+			Server: conn,
+			AppFs:  appFs,
+			Files:  files,
+			Keep:   keep,
+			Result: result,
+		},
+	}
+}
+
+// ChmodAction creates and configures the concrete chmod command.
+func ChmodAction(conn RemoteServer, rules *ChmodRules, dirs, files []string, changes *[]ChmodChange, dryRun bool) *Client {
+	return &Client{
+		Command: &ChmodCommand{
+			Server:  conn,
+			Rules:   rules,
+			Dirs:    dirs,
+			Files:   files,
+			Changes: changes,
+			DryRun:  dryRun,
+		},
+	}
+}
+
+// ListFilesAction creates and configures the concrete list files command.
+func ListFilesAction(conn RemoteServer, files *[]RemoteFile) *Client {
+	return &Client{
+		Command: &ListFilesCommand{
+			Server: conn,
+			Files:  files,
+		},
+	}
+}
+
+// DownloadAction creates and configures the concrete download command.
+func DownloadAction(conn RemoteServer, appFs afero.Fs, files []RemoteFile, basePath, localDir string) *Client {
+	return &Client{
+		Command: &DownloadCommand{
+			Server:   conn,
+			AppFs:    appFs,
+			Files:    files,
+			BasePath: basePath,
+			LocalDir: localDir,
+		},
+	}
+}
+
+// AcquireLockAction creates and configures the concrete acquire lock command.
+func AcquireLockAction(conn RemoteServer, lock *Lock, force bool, previous *Lock, dryRun bool) *Client {
+	return &Client{
+		Command: &AcquireLockCommand{
+			Server:   conn,
+			Lock:     lock,
+			Force:    force,
+			Previous: previous,
+			DryRun:   dryRun,
+		},
+	}
+}
+
+// ReleaseLockAction creates and configures the concrete release lock command.
+func ReleaseLockAction(conn RemoteServer, lock *Lock, dryRun bool) *Client {
+	return &Client{
+		Command: &ReleaseLockCommand{
+			Server: conn,
+			Lock:   lock,
+			DryRun: dryRun,
+		},
+	}
+}
@@ -8,6 +8,11 @@
 package ftpfs
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/spf13/afero"
 )
 
@@ -81,13 +86,13 @@ func (c *UploadCommand) execute() error {
 
 // DeleteAllCommand implements the delete all request.
 type DeleteAllCommand struct {
-	Server      RemoteServer
-	ExcludeList []string
-	DryRun      bool
+	Server RemoteServer
+	Keep   *IgnoreRules
+	DryRun bool
 }
 
 func (c *DeleteAllCommand) execute() error {
-	return c.Server.DeleteAll(c.ExcludeList, c.DryRun)
+	return c.Server.DeleteAll(withLockRule(c.Keep), c.DryRun)
 }
 
 // BackupCommand implements the backup request.
@@ -101,3 +106,344 @@ type BackupCommand struct {
 func (c *BackupCommand) execute() error {
 	return c.Server.DoBackup(c.AppFs, c.Name, c.DryRun)
 }
+
+// ReadManifestCommand implements the read manifest request.
+// Manifest is set to nil when the remote folder has no manifest file,
+// any other read error is returned.
+type ReadManifestCommand struct {
+	Server   RemoteServer
+	Manifest *Manifest
+}
+
+func (c *ReadManifestCommand) execute() error {
+	data, err := c.Server.ReadFile(ManifestFilename)
+	if isNotExist(err) {
+		*c.Manifest = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m, err := ParseManifest(data)
+	if err != nil {
+		return err
+	}
+	*c.Manifest = m
+	return nil
+}
+
+// WriteManifestCommand implements the write manifest request.
+type WriteManifestCommand struct {
+	Server   RemoteServer
+	Manifest Manifest
+	DryRun   bool
+}
+
+func (c *WriteManifestCommand) execute() error {
+	data, err := c.Manifest.Bytes()
+	if err != nil {
+		return err
+	}
+	return c.Server.WriteFile(ManifestFilename, data, c.DryRun)
+}
+
+// DeleteFilesCommand implements the delete files request.
+type DeleteFilesCommand struct {
+	Server RemoteServer
+	Files  []string
+	DryRun bool
+}
+
+func (c *DeleteFilesCommand) execute() error {
+	return c.Server.DeleteFiles(c.Files, c.DryRun)
+}
+
+// RestoreCommand implements the restore request.
+// The remote folder content is replaced by the files within the backup archive.
+// The lock of the running rollback is preserved while the manifest is deleted,
+// so the next deploy uploads all the files.
+type RestoreCommand struct {
+	Server  RemoteServer
+	AppFs   afero.Fs
+	Tarball string
+	Keep    *IgnoreRules
+	DryRun  bool
+}
+
+func (c *RestoreCommand) execute() error {
+	memFs, files, err := extractTarball(c.AppFs, c.Tarball)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("the backup archive '%s' is empty", c.Tarball)
+	}
+
+	if err := c.Server.DeleteAll(withLockRule(c.Keep), c.DryRun); err != nil {
+		return err
+	}
+	if dirs := (Manifest{}).MissingDirs(files); len(dirs) > 0 {
+		if err := c.Server.MakeDirs(dirs, c.DryRun); err != nil {
+			return err
+		}
+	}
+	return c.Server.UploadFiles(memFs, "", files, false, c.DryRun)
+}
+
+// PrepareAtomicCommand implements the request to prepare the folder for an atomic deploy.
+// The stale next folder is replaced by an empty one, the files matching the keep
+// rules are copied over from the live folder and the root folder is set to it.
+type PrepareAtomicCommand struct {
+	Server RemoteServer
+	Keep   *IgnoreRules
+	DryRun bool
+}
+
+func (c *PrepareAtomicCommand) execute() error {
+	live := c.Server.RootFolder()
+	next := NextFolder(live)
+	if err := c.Server.RemoveDir(next, c.DryRun); err != nil {
+		return err
+	}
+	if err := c.Server.MakeDir(next, c.DryRun); err != nil {
+		return err
+	}
+
+	// preserve the kept files and the lock, they would be lost on swap otherwise
+	preserved := map[string][]byte{}
+	keep := withLockRule(c.Keep)
+	if !keep.IsEmpty() {
+		files, err := c.Server.ListFiles()
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if keep.Match(f.Path, false) == nil {
+				continue
+			}
+			if data, err := c.Server.ReadFile(f.Path); err == nil {
+				preserved[f.Path] = data
+			}
+		}
+	}
+
+	if c.DryRun {
+		return nil
+	}
+	c.Server.SetRootFolder(next)
+	names := make([]string, 0, len(preserved))
+	for name := range preserved {
+		names = append(names, name)
+	}
+	if dirs := (Manifest{}).MissingDirs(names); len(dirs) > 0 {
+		if err := c.Server.MakeDirs(dirs, c.DryRun); err != nil {
+			return err
+		}
+	}
+	for name, data := range preserved {
+		if err := c.Server.WriteFile(name, data, c.DryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SwapFoldersCommand implements the request to swap the live folder with the next one.
+// On failure, the live folder is restored from the previous one.
+type SwapFoldersCommand struct {
+	Server     RemoteServer
+	LiveFolder string
+	DryRun     bool
+}
+
+func (c *SwapFoldersCommand) execute() error {
+	next := NextFolder(c.LiveFolder)
+	prev := PrevFolder(c.LiveFolder)
+	c.Server.SetRootFolder(c.LiveFolder)
+
+	if err := c.Server.RemoveDir(prev, c.DryRun); err != nil {
+		return err
+	}
+	liveExists, err := c.Server.Exists(c.LiveFolder)
+	if err != nil {
+		return err
+	}
+	if liveExists {
+		if err := c.Server.Rename(c.LiveFolder, prev, c.DryRun); err != nil {
+			return err
+		}
+	}
+	if err := c.Server.Rename(next, c.LiveFolder, c.DryRun); err != nil {
+		if liveExists {
+			_ = c.Server.Rename(prev, c.LiveFolder, c.DryRun)
+		}
+		return fmt.Errorf("could not move '%s' to '%s', got error '%s'", next, c.LiveFolder, err.Error())
+	}
+	return nil
+}
+
+// CleanupAtomicCommand implements the request to remove the next folder after a failed atomic deploy.
+type CleanupAtomicCommand struct {
+	Server     RemoteServer
+	LiveFolder string
+	DryRun     bool
+}
+
+func (c *CleanupAtomicCommand) execute() error {
+	c.Server.SetRootFolder(c.LiveFolder)
+	return c.Server.RemoveDir(NextFolder(c.LiveFolder), c.DryRun)
+}
+
+// VerifyCommand implements the request to compare the remote folder content with the local build.
+type VerifyCommand struct {
+	Server RemoteServer
+	AppFs  afero.Fs
+	Files  map[string]string
+	Keep   *IgnoreRules
+	Result *VerifyResult
+}
+
+func (c *VerifyCommand) execute() error {
+	result, err := verify(c.Server, c.AppFs, c.Files, c.Keep)
+	if err != nil {
+		return err
+	}
+	*c.Result = *result
+	return nil
+}
+
+// ChmodCommand implements the request to set the permissions of the remote folders and files
+// matching the chmod rules. Paths are relative to the remote folder.
+type ChmodCommand struct {
+	Server  RemoteServer
+	Rules   *ChmodRules
+	Dirs    []string
+	Files   []string
+	Changes *[]ChmodChange
+	DryRun  bool
+}
+
+func (c *ChmodCommand) execute() error {
+	changes, err := chmod(c.Server, c.Rules, c.Dirs, c.Files, c.DryRun)
+	if err != nil {
+		return err
+	}
+	*c.Changes = changes
+	return nil
+}
+
+// ListFilesCommand implements the request to list the files within the remote folder.
+type ListFilesCommand struct {
+	Server RemoteServer
+	Files  *[]RemoteFile
+}
+
+func (c *ListFilesCommand) execute() error {
+	files, err := c.Server.ListFiles()
+	if err != nil {
+		return err
+	}
+	*c.Files = files
+	return nil
+}
+
+// DownloadCommand implements the request to download remote files to a local folder.
+// Files are saved with their path relative to BasePath, within the remote folder.
+type DownloadCommand struct {
+	Server   RemoteServer
+	AppFs    afero.Fs
+	Files    []RemoteFile
+	BasePath string
+	LocalDir string
+}
+
+func (c *DownloadCommand) execute() error {
+	for _, f := range c.Files {
+		data, err := c.Server.ReadFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("could not download '%s', got error '%s'", f.Path, err.Error())
+		}
+		rel := f.Path
+		if c.BasePath != "" {
+			rel = strings.TrimPrefix(rel, c.BasePath+"/")
+		}
+		localFile := filepath.Join(c.LocalDir, filepath.FromSlash(rel))
+		if err := c.AppFs.MkdirAll(filepath.Dir(localFile), 0755); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(c.AppFs, localFile, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AcquireLockCommand implements the request to lock the remote folder for the deploy.
+// A fresh lock held by another deploy makes it fail with a LockedError, unless Force is set.
+// The replaced lock, if any, is set to Previous when not nil.
+type AcquireLockCommand struct {
+	Server   RemoteServer
+	Lock     *Lock
+	Force    bool
+	Previous *Lock
+	DryRun   bool
+}
+
+func (c *AcquireLockCommand) execute() error {
+	if data, err := c.Server.ReadFile(LockFilename); err == nil {
+		// a not valid lock file is replaced
+		if existing, err := ParseLock(data); err == nil {
+			if !existing.IsStale(time.Now()) && !c.Force {
+				return &LockedError{Lock: existing}
+			}
+			if c.Previous != nil {
+				*c.Previous = *existing
+			}
+		}
+	}
+
+	data, err := c.Lock.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := c.Server.WriteFile(LockFilename, data, c.DryRun); err != nil {
+		return err
+	}
+	if c.DryRun {
+		return nil
+	}
+
+	// another deploy may have written its own lock in the meantime
+	data, err = c.Server.ReadFile(LockFilename)
+	if err != nil {
+		return err
+	}
+	current, err := ParseLock(data)
+	if err != nil {
+		return err
+	}
+	if current.ID != c.Lock.ID {
+		return &LockedError{Lock: current}
+	}
+	return nil
+}
+
+// ReleaseLockCommand implements the request to unlock the remote folder.
+// The lock file is deleted only when held by the Lock.
+type ReleaseLockCommand struct {
+	Server RemoteServer
+	Lock   *Lock
+	DryRun bool
+}
+
+func (c *ReleaseLockCommand) execute() error {
+	data, err := c.Server.ReadFile(LockFilename)
+	if err != nil {
+		// nothing to release
+		return nil
+	}
+	if current, err := ParseLock(data); err == nil && current.ID != c.Lock.ID {
+		return nil
+	}
+	return c.Server.DeleteFiles([]string{LockFilename}, c.DryRun)
+}
@@ -8,21 +8,119 @@
 package ftpfs
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
 	"strconv"
 	"strings"
 )
 
+// Protocols supported to deploy the website on a remote server.
+const (
+	ProtocolFTP  string = "ftp"
+	ProtocolSFTP string = "sftp"
+	ProtocolS3   string = "s3"
+)
+
+// DefaultS3Region is the region used when none is set for the S3 bucket.
+const DefaultS3Region = "us-east-1"
+
+// TLS modes supported by the FTP connection (FTPS).
+const (
+	TLSModeNone     string = ""
+	TLSModeExplicit string = "explicit"
+	TLSModeImplicit string = "implicit"
+)
+
+// SupportedProtocols returns the list of protocols available to deploy the website.
+func SupportedProtocols() []string {
+	return []string{ProtocolFTP, ProtocolSFTP, ProtocolS3}
+}
+
+// SupportedTLSModes returns the list of TLS modes available for the FTP connection.
+func SupportedTLSModes() []string {
+	return []string{TLSModeExplicit, TLSModeImplicit}
+}
+
 // FTPConnectionConfig is the struct with all is needed to
 // establish an FTP connection to a remote server.
+// TLSMode set to explicit (AUTH TLS) or implicit enables FTP over TLS (FTPS)
+// for both the control and the data channels.
 type FTPConnectionConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Timeout  int
-	IsEPSV   bool
+	Host                  string
+	Port                  int
+	User                  string
+	Password              string
+	Timeout               int
+	IsEPSV                bool
+	TLSMode               string
+	TLSInsecureSkipVerify bool
+	TLSCAFile             string
 }
 
 func (d *FTPConnectionConfig) makeConnectionString() string {
 	return strings.Join([]string{d.Host, strconv.Itoa(d.Port)}, ":")
 }
+
+func (d *FTPConnectionConfig) makeTLSConfig() (*tls.Config, error) {
+	// #nosec G402 -- InsecureSkipVerify is explicitly requested by the user with FTP_TLS_INSECURE_SKIP_VERIFY
+	tlsConfig := &tls.Config{
+		ServerName:         d.Host,
+		InsecureSkipVerify: d.TLSInsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+		// servers requiring the data connections to reuse the TLS session of the control one
+		// (e.g. vsftpd require_ssl_reuse) refuse the transfers otherwise
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+
+	if d.TLSCAFile != "" {
+		caCert, err := os.ReadFile(d.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the CA bundle '%s', got error '%s'", d.TLSCAFile, err.Error())
+		}
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			certPool = x509.NewCertPool()
+		}
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("could not find any valid certificate in the CA bundle '%s'", d.TLSCAFile)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	return tlsConfig, nil
+}
+
+// SFTPConnectionConfig is the struct with all is needed to
+// establish an SFTP (SSH File Transfer Protocol) connection to a remote server.
+// When KeyPath is set, key based authentication is tried before the password one.
+type SFTPConnectionConfig struct {
+	Host                  string
+	Port                  int
+	User                  string
+	Password              string
+	Timeout               int
+	KeyPath               string
+	KeyPassphrase         string
+	KnownHostsPath        string
+	InsecureIgnoreHostKey bool
+}
+
+func (d *SFTPConnectionConfig) makeConnectionString() string {
+	return strings.Join([]string{d.Host, strconv.Itoa(d.Port)}, ":")
+}
+
+// S3ConnectionConfig is the struct with all is needed to
+// deploy on an S3 compatible object storage bucket.
+// Endpoint is the URL of the S3 compatible service (MinIO, Cloudflare R2, ...),
+// when not set the AWS S3 endpoint for the region is used. SessionToken is set for temporary
+// credentials only. Timeout applies to the connection only.
+type S3ConnectionConfig struct {
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Timeout         int
+}
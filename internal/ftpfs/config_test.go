@@ -0,0 +1,158 @@
+package ftpfs
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1.
+func newTestCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// fakeFTPSServer serves a single implicit TLS control connection and a file over a protected data
+// connection. As vsftpd with require_ssl_reuse, the data connection is refused unless it resumes
+// the TLS session of the control connection.
+func fakeFTPSServer(t *testing.T, file, content string) (string, int) {
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{newTestCertificate(t)}, MinVersion: tls.VersionTLS12}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		dataListener.Close()
+	})
+
+	replies := map[string]string{
+		"USER me":     "331 password required",
+		"PASS secret": "230 logged in",
+		"TYPE I":      "200 binary",
+		"PBSZ 0":      "200 PBSZ=0",
+		"PROT P":      "200 protection level set to private",
+		"CWD /www":    "250 directory changed",
+		"EPSV":        fmt.Sprintf("229 Entering Extended Passive Mode (|||%d|)", dataListener.Addr().(*net.TCPAddr).Port),
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("220 ready\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case line == "QUIT":
+				_, _ = conn.Write([]byte("221 bye\r\n"))
+				return
+			case line == "RETR "+file:
+				_, _ = conn.Write([]byte("150 opening data connection\r\n"))
+				_, _ = conn.Write([]byte(serveTLSData(dataListener, tlsConfig, content) + "\r\n"))
+			default:
+				reply, ok := replies[line]
+				if !ok {
+					reply = "502 not implemented"
+				}
+				_, _ = conn.Write([]byte(reply + "\r\n"))
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+// serveTLSData sends the content over the next data connection and returns the reply for the control connection.
+func serveTLSData(listener net.Listener, tlsConfig *tls.Config, content string) string {
+	conn, err := listener.Accept()
+	if err != nil {
+		return "425 can not open data connection"
+	}
+	tlsConn := tls.Server(conn, tlsConfig)
+	defer tlsConn.Close()
+	if err := tlsConn.Handshake(); err != nil {
+		return "522 TLS handshake failed"
+	}
+	if !tlsConn.ConnectionState().DidResume {
+		return "522 TLS session reuse required"
+	}
+	if _, err := tlsConn.Write([]byte(content)); err != nil {
+		return "426 transfer aborted"
+	}
+	return "226 transfer complete"
+}
+
+func TestMakeTLSConfig(t *testing.T) {
+	is := is.New(t)
+
+	config := &FTPConnectionConfig{Host: "example.com", TLSMode: TLSModeExplicit}
+	tlsConfig, err := config.makeTLSConfig()
+	is.NoErr(err)
+	is.Equal("example.com", tlsConfig.ServerName)
+	is.True(tlsConfig.ClientSessionCache != nil)
+
+	config.TLSCAFile = "missing.pem"
+	_, err = config.makeTLSConfig()
+	is.True(err != nil)
+}
+
+func TestFTPSSessionReuse(t *testing.T) {
+	is := is.New(t)
+
+	host, port := fakeFTPSServer(t, "index.html", "<h1>Hello</h1>")
+	conn := NewFTPServerConnection(&FTPConnectionConfig{
+		Host:                  host,
+		Port:                  port,
+		User:                  "me",
+		Password:              "secret",
+		Timeout:               5,
+		TLSMode:               TLSModeImplicit,
+		TLSInsecureSkipVerify: true,
+	})
+	conn.SetRootFolder("/www")
+
+	is.NoErr(conn.connect())
+	data, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal("<h1>Hello</h1>", string(data))
+	is.NoErr(conn.client.Quit())
+}
@@ -5,24 +5,27 @@
  * that can be found in the LICENSE file.
  */
 
-// Package ftpfs handle connections and operations to deal with an FTP server.
+// Package ftpfs handle connections and operations to deal with a remote server over FTP or SFTP.
 package ftpfs
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
+	"net/textproto"
+	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jlaffaye/ftp"
 	"github.com/spf13/afero"
 	"github.com/sveltinio/prompti/progressbar"
-	"github.com/sveltinio/sveltin/common"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
 	"github.com/sveltinio/sveltin/utils"
 	"github.com/sveltinio/yinlog"
 )
@@ -33,22 +36,35 @@ type FTPServerConnection struct {
 	serverFolder string
 	client       *ftp.ServerConn
 	logger       *yinlog.Logger
+	concurrency  int
+	journal      *Journal
+	plain        bool
+	control      *controlConn
+	hashCommand  string
 }
 
 // NewFTPServerConnection returns a new FTPServerConnection struct.
 func NewFTPServerConnection(config *FTPConnectionConfig) *FTPServerConnection {
 	return &FTPServerConnection{
 		Config: FTPConnectionConfig{
-			Host:     config.Host,
-			Port:     config.Port,
-			User:     config.User,
-			Password: config.Password,
-			Timeout:  config.Timeout,
-			IsEPSV:   config.IsEPSV,
+			Host:                  config.Host,
+			Port:                  config.Port,
+			User:                  config.User,
+			Password:              config.Password,
+			Timeout:               config.Timeout,
+			IsEPSV:                config.IsEPSV,
+			TLSMode:               config.TLSMode,
+			TLSInsecureSkipVerify: config.TLSInsecureSkipVerify,
+			TLSCAFile:             config.TLSCAFile,
 		},
 	}
 }
 
+// RootFolder returns the root folder on the FTP remote server.
+func (s *FTPServerConnection) RootFolder() string {
+	return s.serverFolder
+}
+
 // SetRootFolder sets the root folder on the FTP remote server.
 func (s *FTPServerConnection) SetRootFolder(name string) {
 	s.serverFolder = name
@@ -59,16 +75,35 @@ func (s *FTPServerConnection) SetLogger(logger *yinlog.Logger) {
 	s.logger = logger
 }
 
+// SetPlainOutput sets whether to log each step instead of rendering progress bars.
+func (s *FTPServerConnection) SetPlainOutput(plain bool) {
+	s.plain = plain
+}
+
+// SetJournal sets the journal recording the uploaded files.
+func (s *FTPServerConnection) SetJournal(journal *Journal) {
+	s.journal = journal
+}
+
+// SetConcurrency sets the number of connections used to upload files.
+func (s *FTPServerConnection) SetConcurrency(n int) {
+	s.concurrency = n
+}
+
 // Dial contains the logic for the FTP receiver to handle the dial command.
 func (s *FTPServerConnection) Dial() error {
 	connStr := s.Config.makeConnectionString()
-	s.logger.Infof("Connecting to the FTP Server (%s) ", connStr)
-	c, err := ftp.Dial(connStr, ftp.DialWithTimeout(time.Duration(s.Config.Timeout)*time.Second), ftp.DialWithDisabledEPSV(s.Config.IsEPSV))
-	if err != nil {
-		return err
+	tlsMode := strings.ToLower(s.Config.TLSMode)
+	switch tlsMode {
+	case TLSModeExplicit, TLSModeImplicit:
+		s.logger.Infof("Connecting to the FTP Server over %s TLS (%s) ", tlsMode, connStr)
+		if s.Config.TLSInsecureSkipVerify {
+			s.logger.Important("The TLS certificate of the FTP server will not be verified!")
+		}
+	default:
+		s.logger.Infof("Connecting to the FTP Server (%s) ", connStr)
 	}
-	s.client = c
-	return nil
+	return s.dial()
 }
 
 // Login contains the logic for the FTP receiver to handle the login command.
@@ -83,6 +118,9 @@ func (s *FTPServerConnection) Login() error {
 // Logout contains the logic for the FTP receiver to handle the logout command.
 func (s *FTPServerConnection) Logout() error {
 	s.logger.Info("Closing the connection to the FTP server")
+	if s.control != nil {
+		s.control.close()
+	}
 	if err := s.client.Quit(); err != nil {
 		return err
 	}
@@ -109,16 +147,17 @@ func (s *FTPServerConnection) MakeDirs(folders []string, dryRun bool) error {
 		},
 	}
 
-	if _, err := progressbar.Run(pbConfig); err != nil {
-		return err
-	}
-	return nil
+	return runSteps(s.logger, s.plain, pbConfig)
 }
 
 // UploadFiles contains the logic for the FTP receiver to handle the upload files command.
 func (s *FTPServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
 	sort.Strings(files)
 
+	if s.concurrency > 1 && !dryRun {
+		return s.uploadFilesConcurrently(appFs, localDir, files, replaceBasePath)
+	}
+
 	pbConfig := &progressbar.Config{
 		Items:          files,
 		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded", len(files)),
@@ -127,14 +166,12 @@ func (s *FTPServerConnection) UploadFiles(appFs afero.Fs, localDir string, files
 		},
 	}
 
-	if _, err := progressbar.Run(pbConfig); err != nil {
-		return err
-	}
-	return nil
+	return runSteps(s.logger, s.plain, pbConfig)
 }
 
 // DeleteAll contains the logic for the FTP receiver to handle the delete all command.
-func (s *FTPServerConnection) DeleteAll(exclude []string, dryrun bool) error {
+// Files and folders matching the keep rules are not deleted.
+func (s *FTPServerConnection) DeleteAll(keep *IgnoreRules, dryrun bool) error {
 	entries, err := s.client.List(s.serverFolder)
 	if err != nil {
 		return err
@@ -142,40 +179,147 @@ func (s *FTPServerConnection) DeleteAll(exclude []string, dryrun bool) error {
 
 	if len(entries) > 0 {
 		s.logger.Important("Deleting previous content from the FTP remote folder")
-		for _, entry := range entries {
-			switch entry.Type {
-			case ftp.EntryTypeFolder:
-				if !dryrun {
-					folder := filepath.Join(s.serverFolder, entry.Name)
-					if err := s.client.RemoveDirRecur(folder); err != nil {
-						return err
-					}
-				}
-			case ftp.EntryTypeFile:
-				if !dryrun {
-					file := filepath.Join(s.serverFolder, entry.Name)
-					if !common.Contains(exclude, filepath.Base(file)) {
-						if err := s.client.Delete(file); err != nil {
-							return nil
-						}
-					}
+		if _, err := s.deleteTree("", keep, dryrun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListFiles returns the list of the files within the FTP remote folder.
+func (s *FTPServerConnection) ListFiles() ([]RemoteFile, error) {
+	w := s.client.Walk(s.serverFolder)
+	files := []RemoteFile{}
+	for w.Next() {
+		if entry := w.Stat(); entry.Type == ftp.EntryTypeFile {
+			files = append(files, RemoteFile{
+				Path:    utils.ToBasePath(w.Path(), s.serverFolder),
+				Size:    int64(entry.Size),
+				ModTime: entry.Time,
+			})
+		}
+	}
+	if err := w.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// HashAlgorithm returns the checksum algorithm supported by the FTP server, among the HASH,
+// XCRC, MD5 and XMD5 extensions. SHA-256 is preferred when HASH supports it.
+func (s *FTPServerConnection) HashAlgorithm() (string, error) {
+	if err := s.openControl(); err != nil {
+		return "", err
+	}
+
+	if s.control.hasFeature("HASH") {
+		// the current algorithm is marked with *, the others are selected with OPTS HASH
+		listed := map[string]bool{}
+		current := ""
+		for _, name := range strings.Split(strings.ToUpper(s.control.features["HASH"]), ";") {
+			if strings.HasSuffix(name, "*") {
+				name = strings.TrimSuffix(name, "*")
+				current = name
+			}
+			listed[name] = true
+		}
+		for _, algorithm := range []string{HashSHA256, HashSHA1, HashMD5, HashCRC32} {
+			if !listed[algorithm] {
+				continue
+			}
+			if algorithm != current {
+				if _, _, err := s.control.cmd(200, "OPTS HASH %s", algorithm); err != nil {
+					continue
 				}
 			}
+			s.hashCommand = "HASH"
+			return algorithm, nil
 		}
 	}
 
-	return nil
+	switch {
+	case s.control.hasFeature("XCRC"):
+		s.hashCommand = "XCRC"
+		return HashCRC32, nil
+	case s.control.hasFeature("MD5"), s.control.hasFeature("XMD5"):
+		s.hashCommand = "MD5"
+		if !s.control.hasFeature("MD5") {
+			s.hashCommand = "XMD5"
+		}
+		return HashMD5, nil
+	default:
+		return "", nil
+	}
+}
+
+// Hash returns the hex encoded checksum of the file, as computed by the FTP server.
+// The algorithm is negotiated by HashAlgorithm when not done yet.
+func (s *FTPServerConnection) Hash(name string) (string, error) {
+	if s.control == nil || s.hashCommand == "" {
+		algorithm, err := s.HashAlgorithm()
+		if err != nil {
+			return "", err
+		}
+		if algorithm == "" {
+			return "", errors.New("the FTP server does not support any checksum command")
+		}
+	}
+	remotePath := path.Join(s.serverFolder, filepath.ToSlash(name))
+	_, reply, err := s.control.cmd(-1, "%s %s", s.hashCommand, remotePath)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(reply)
+	switch {
+	// 213 <algorithm> <range> <hash> <filename>
+	case s.hashCommand == "HASH" && len(fields) >= 3:
+		return strings.ToLower(fields[2]), nil
+	// 250 <hash>, 251 <filename> <hash>
+	case s.hashCommand == "XCRC" && len(fields) >= 1:
+		// leading zeros may be omitted
+		sum := strings.ToLower(fields[len(fields)-1])
+		if len(sum) < 8 {
+			sum = strings.Repeat("0", 8-len(sum)) + sum
+		}
+		return sum, nil
+	case len(fields) >= 1:
+		return strings.ToLower(fields[len(fields)-1]), nil
+	default:
+		return "", errUnexpectedReply(s.hashCommand, reply)
+	}
+}
+
+// Chmod sets the permissions of the file or folder with the SITE CHMOD command.
+func (s *FTPServerConnection) Chmod(name string, mode os.FileMode) error {
+	if err := s.openControl(); err != nil {
+		return err
+	}
+	remotePath := path.Join(s.serverFolder, filepath.ToSlash(name))
+	code, message, err := s.control.cmd(-1, "SITE CHMOD %03o %s", mode.Perm(), remotePath)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case 200, 250:
+		return nil
+	// unknown command, not implemented, not implemented for that parameter
+	case 500, 502, 504:
+		return ErrChmodNotSupported
+	default:
+		return &textproto.Error{Code: code, Msg: message}
+	}
 }
 
 // DoBackup contains the logic for the FTP receiver to handle the backup command.
 func (s *FTPServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
 	archiveFilename := tarballFilePath + "_" + time.Now().Format("20060102_3:4:5PM") + ".tar.gz"
 	s.logger.Infof("Reading the remote folder: %s", s.serverFolder)
-	remoteFiles := s.walkRemote()
+	remoteFiles := backupFiles(s.walkRemote())
 
 	if !dryRun {
 		if len(remoteFiles) > 0 {
-			if err := s.createTarball(appFs, archiveFilename, remoteFiles, dryRun); err != nil {
+			if err := createTarball(s.logger, s.plain, appFs, archiveFilename, remoteFiles, s.retrieve, dryRun); err != nil {
 				return err
 			}
 		} else {
@@ -185,99 +329,284 @@ func (s *FTPServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, d
 	return nil
 }
 
-//=============================================================================
-
-func (s *FTPServerConnection) walkRemote() []string {
-	w := s.client.Walk(s.serverFolder)
-	var remoteFiles []string
-	for w.Next() {
-		if w.Stat().Type == ftp.EntryTypeFile {
-			remoteFiles = append(remoteFiles, utils.ToBasePath(w.Path(), s.serverFolder))
-		}
-	}
-	return remoteFiles
+// ReadFile contains the logic for the FTP receiver to retrieve a file from the remote folder.
+func (s *FTPServerConnection) ReadFile(name string) ([]byte, error) {
+	return s.retrieve(name)
 }
 
-func (s *FTPServerConnection) uploadSingle(filename string, data *bytes.Buffer, dryRun bool) error {
-	saveTo := filepath.Join(s.serverFolder, filepath.Dir(filename))
-	saveAs := filepath.Base(filename)
+// WriteFile contains the logic for the FTP receiver to store a file on the remote folder.
+func (s *FTPServerConnection) WriteFile(name string, data []byte, dryRun bool) error {
+	return s.uploadSingle(name, bytes.NewBuffer(data), dryRun)
+}
 
-	if !dryRun {
-		cwd, _ := s.client.CurrentDir()
-		if cwd != saveTo {
-			if err := s.client.ChangeDir(saveTo); err != nil {
+// DeleteFiles contains the logic for the FTP receiver to handle the delete files command.
+func (s *FTPServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	for _, file := range files {
+		if !dryRun {
+			if err := s.client.Delete(filepath.Join(s.serverFolder, file)); err != nil {
 				return err
 			}
 		}
-		if err := s.client.Stor(saveAs, data); err != nil {
-			return err
+	}
+	return nil
+}
+
+// Exists returns true if the path exists on the FTP remote server.
+// A missing parent folder is not an error, any other listing failure is returned.
+func (s *FTPServerConnection) Exists(name string) (bool, error) {
+	entries, err := s.client.List(path.Dir(name))
+	if isNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Name == path.Base(name) {
+			return true, nil
 		}
 	}
+	return false, nil
+}
 
-	return nil
+// MakeDir creates the folder on the FTP remote server.
+func (s *FTPServerConnection) MakeDir(name string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.client.MakeDir(name)
+}
+
+// Rename renames the path on the FTP remote server.
+func (s *FTPServerConnection) Rename(from, to string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.client.Rename(from, to)
+}
+
+// RemoveDir deletes the folder and all its content from the FTP remote server, if it exists.
+func (s *FTPServerConnection) RemoveDir(name string, dryRun bool) error {
+	exists, err := s.Exists(name)
+	if err != nil || !exists || dryRun {
+		return err
+	}
+	return s.client.RemoveDirRecur(name)
 }
 
 //=============================================================================
 
-func (s *FTPServerConnection) createTarball(appFs afero.Fs, tarballFilePath string, filePaths []string, dryRun bool) error {
-	s.logger.Info("Creating the backup archive...")
-	// In-memory file system
-	memFs := afero.NewMemMapFs()
-	// Create a new archive file
-	file, err := appFs.Create(tarballFilePath)
+// openControl opens the control connection used for the commands not supported by the FTP client library, once.
+func (s *FTPServerConnection) openControl() error {
+	if s.control != nil {
+		return nil
+	}
+	control, err := dialControl(&s.Config)
 	if err != nil {
-		return fmt.Errorf("could not create tarball file '%s', got error '%s'", tarballFilePath, err.Error())
+		return err
 	}
-	defer file.Close()
+	s.control = control
+	return nil
+}
 
-	gzipWriter := gzip.NewWriter(file)
-	defer gzipWriter.Close()
+func (s *FTPServerConnection) dial() error {
+	dialOptions := []ftp.DialOption{
+		ftp.DialWithTimeout(time.Duration(s.Config.Timeout) * time.Second),
+		ftp.DialWithDisabledEPSV(s.Config.IsEPSV),
+	}
 
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
+	switch strings.ToLower(s.Config.TLSMode) {
+	case TLSModeNone:
+	case TLSModeExplicit:
+		tlsConfig, err := s.Config.makeTLSConfig()
+		if err != nil {
+			return err
+		}
+		dialOptions = append(dialOptions, ftp.DialWithExplicitTLS(tlsConfig))
+	case TLSModeImplicit:
+		tlsConfig, err := s.Config.makeTLSConfig()
+		if err != nil {
+			return err
+		}
+		dialOptions = append(dialOptions, ftp.DialWithTLS(tlsConfig))
+	default:
+		return sveltinerr.NewOptionNotValidError(s.Config.TLSMode, SupportedTLSModes())
+	}
 
-	pbConfig := &progressbar.Config{
-		Items:          filePaths,
-		OnCompletesMsg: fmt.Sprintf("Backup done! Saved as: %s", tarballFilePath),
-		OnProgressCmd: func(path string) tea.Cmd {
-			return createTarballTeaCmd(s, memFs, tarWriter, path, dryRun)
-		},
+	c, err := ftp.Dial(s.Config.makeConnectionString(), dialOptions...)
+	if err != nil {
+		return err
 	}
+	s.client = c
+	return nil
+}
 
-	if _, err := progressbar.Run(pbConfig); err != nil {
+// connect dials and logs in without any output, it is used by the upload pool workers.
+func (s *FTPServerConnection) connect() error {
+	if err := s.dial(); err != nil {
 		return err
 	}
+	return s.client.Login(s.Config.User, s.Config.Password)
+}
 
-	return nil
+// reconnect replaces the connection to the FTP server with a new logged-in one.
+func (s *FTPServerConnection) reconnect() error {
+	if s.client != nil {
+		_ = s.client.Quit()
+	}
+	return s.connect()
 }
 
-func addToTarWriter(memFs afero.Fs, filePath string, tarWriter *tar.Writer) error {
-	file, err := memFs.Open(filePath)
+// isDir returns true if the folder, relative to the root one, exists on the FTP server.
+func (s *FTPServerConnection) isDir(name string) bool {
+	cwd, err := s.client.CurrentDir()
 	if err != nil {
-		return fmt.Errorf("could not open file '%s', got error '%s'", filePath, err.Error())
+		return false
 	}
-	defer file.Close()
+	defer func() { _ = s.client.ChangeDir(cwd) }()
+	return s.client.ChangeDir(filepath.Join(s.serverFolder, name)) == nil
+}
 
-	stat, err := file.Stat()
-	if err != nil {
-		return fmt.Errorf("could not get stat for file '%s', got error '%s'", filePath, err.Error())
+// uploadFilesConcurrently spreads the files upload over a pool of logged-in connections.
+func (s *FTPServerConnection) uploadFilesConcurrently(appFs afero.Fs, localDir string, files []string, replaceBasePath bool) error {
+	s.logger.Infof("Opening %d connections to the FTP server", s.concurrency)
+	conns := []*FTPServerConnection{}
+	defer func() {
+		for _, c := range conns {
+			_ = c.client.Quit()
+		}
+	}()
+
+	workers := []*poolWorker{}
+	for i := 0; i < s.concurrency; i++ {
+		c := NewFTPServerConnection(&s.Config)
+		c.SetRootFolder(s.serverFolder)
+		c.SetJournal(s.journal)
+		if err := c.connect(); err != nil {
+			return err
+		}
+		conns = append(conns, c)
+		workers = append(workers, &poolWorker{
+			upload: func(file string) error {
+				return c.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+			},
+			reconnect: c.reconnect,
+		})
 	}
 
-	header := &tar.Header{
-		Name:    filePath,
-		Size:    stat.Size(),
-		Mode:    int64(stat.Mode()),
-		ModTime: stat.ModTime(),
+	return runUploadPool(s.logger, s.plain, files, workers, fmt.Sprintf("Done! %d files uploaded", len(files)))
+}
+
+func (s *FTPServerConnection) uploadLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	fileBytes, err := afero.ReadFile(appFs, file)
+	if err != nil {
+		return err
+	}
+	remoteFile := file
+	if replaceBasePath {
+		remoteFile = utils.ToBasePath(file, localDir)
 	}
+	if err := s.uploadSingle(remoteFile, bytes.NewBuffer(fileBytes), false); err != nil {
+		return err
+	}
+	return s.journal.Record(remoteFile)
+}
 
-	err = tarWriter.WriteHeader(header)
+// deleteTree deletes the content of the folder, relative to the remote one, except the paths
+// matching the keep rules. It returns true if something has been kept.
+func (s *FTPServerConnection) deleteTree(dir string, keep *IgnoreRules, dryRun bool) (bool, error) {
+	entries, err := s.client.List(path.Join(s.serverFolder, dir))
 	if err != nil {
-		return fmt.Errorf("could not write header for file '%s', got error '%s'", filePath, err.Error())
+		return false, err
 	}
 
-	_, err = io.Copy(tarWriter, file)
+	kept := false
+	for _, entry := range entries {
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+		name := path.Join(dir, entry.Name)
+		isDir := entry.Type == ftp.EntryTypeFolder
+		if rule := keep.Match(name, isDir); rule != nil {
+			logKept(s.logger, name, rule, dryRun)
+			kept = true
+			continue
+		}
+
+		remotePath := path.Join(s.serverFolder, name)
+		switch {
+		case isDir && keep.IsEmpty():
+			if !dryRun {
+				if err := s.client.RemoveDirRecur(remotePath); err != nil {
+					return kept, err
+				}
+			}
+		case isDir:
+			// rules may match paths within the folder
+			subKept, err := s.deleteTree(name, keep, dryRun)
+			if err != nil {
+				return kept, err
+			}
+			if subKept {
+				kept = true
+			} else if !dryRun {
+				if err := s.client.RemoveDir(remotePath); err != nil {
+					return kept, err
+				}
+			}
+		case entry.Type == ftp.EntryTypeFile:
+			if !dryRun {
+				if err := s.client.Delete(remotePath); err != nil {
+					return kept, err
+				}
+			}
+		}
+	}
+	return kept, nil
+}
+
+func (s *FTPServerConnection) walkRemote() []string {
+	w := s.client.Walk(s.serverFolder)
+	var remoteFiles []string
+	for w.Next() {
+		if w.Stat().Type == ftp.EntryTypeFile {
+			remoteFiles = append(remoteFiles, utils.ToBasePath(w.Path(), s.serverFolder))
+		}
+	}
+	return remoteFiles
+}
+
+func (s *FTPServerConnection) retrieve(file string) ([]byte, error) {
+	if err := s.client.ChangeDir(filepath.Join(s.serverFolder, filepath.Dir(file))); err != nil {
+		return nil, err
+	}
+	// fetch the file from the remote FTP server
+	r, err := s.client.Retr(filepath.Base(file))
 	if err != nil {
-		return fmt.Errorf("could not copy the file '%s' data to the tarball, got error '%s'", filePath, err.Error())
+		return nil, err
+	}
+	// retrieve the file content, a failed transfer is reported by Close
+	data, err := io.ReadAll(r)
+	if cerr := r.Close(); err == nil {
+		err = cerr
+	}
+	return data, err
+}
+
+func (s *FTPServerConnection) uploadSingle(filename string, data *bytes.Buffer, dryRun bool) error {
+	saveTo := filepath.Join(s.serverFolder, filepath.Dir(filename))
+	saveAs := filepath.Base(filename)
+
+	if !dryRun {
+		cwd, _ := s.client.CurrentDir()
+		if cwd != saveTo {
+			if err := s.client.ChangeDir(saveTo); err != nil {
+				return err
+			}
+		}
+		if err := s.client.Stor(saveAs, data); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,26 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestFTPExistsListError(t *testing.T) {
+	is := is.New(t)
+
+	// no passive mode, the listing can not open the data connection
+	host, port, _ := fakeFTPServer(t, map[string]string{
+		"USER me":     "331 password required",
+		"PASS secret": "230 logged in",
+		"TYPE I":      "200 binary",
+		"EPSV":        "421 service not available",
+		"PASV":        "421 service not available",
+	})
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: host, Port: port, User: "me", Password: "secret", Timeout: 5})
+	is.NoErr(conn.connect())
+
+	exists, err := conn.Exists("/www/index.html")
+	is.True(err != nil)
+	is.True(!exists)
+}
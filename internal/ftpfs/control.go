@@ -0,0 +1,146 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+)
+
+// controlConn is a minimal FTP control connection used to send the commands not
+// supported by the FTP client library (e.g. HASH, XCRC). No data connection is ever opened.
+type controlConn struct {
+	conn     *textproto.Conn
+	features map[string]string
+}
+
+// dialControl opens and logs in a control connection to the FTP server.
+func dialControl(config *FTPConnectionConfig) (*controlConn, error) {
+	dialer := &net.Dialer{Timeout: time.Duration(config.Timeout) * time.Second}
+	addr := config.makeConnectionString()
+
+	var netConn net.Conn
+	var err error
+	switch strings.ToLower(config.TLSMode) {
+	case TLSModeNone, TLSModeExplicit:
+		netConn, err = dialer.Dial("tcp", addr)
+	case TLSModeImplicit:
+		tlsConfig, tlsErr := config.makeTLSConfig()
+		if tlsErr != nil {
+			return nil, tlsErr
+		}
+		netConn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	default:
+		return nil, sveltinerr.NewOptionNotValidError(config.TLSMode, SupportedTLSModes())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &controlConn{conn: textproto.NewConn(netConn), features: map[string]string{}}
+	if _, _, err := c.conn.ReadResponse(220); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	if strings.ToLower(config.TLSMode) == TLSModeExplicit {
+		if _, _, err := c.cmd(234, "AUTH TLS"); err != nil {
+			c.close()
+			return nil, err
+		}
+		tlsConfig, err := config.makeTLSConfig()
+		if err != nil {
+			c.close()
+			return nil, err
+		}
+		c.conn = textproto.NewConn(tls.Client(netConn, tlsConfig))
+	}
+
+	if err := c.login(config.User, config.Password); err != nil {
+		c.close()
+		return nil, err
+	}
+	if err := c.feat(); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// cmd sends the command and reads the response, failing when the code is not the expected one.
+func (c *controlConn) cmd(expected int, format string, args ...interface{}) (int, string, error) {
+	if _, err := c.conn.Cmd(format, args...); err != nil {
+		return 0, "", err
+	}
+	return c.conn.ReadResponse(expected)
+}
+
+func (c *controlConn) login(user, password string) error {
+	code, message, err := c.cmd(-1, "USER %s", user)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case 230:
+		return nil
+	case 331:
+		_, _, err := c.cmd(230, "PASS %s", password)
+		return err
+	default:
+		return &textproto.Error{Code: code, Msg: message}
+	}
+}
+
+// feat reads the extensions supported by the FTP server, as upper case names and their parameters.
+func (c *controlConn) feat() error {
+	code, message, err := c.cmd(-1, "FEAT")
+	if err != nil {
+		return err
+	}
+	// servers not supporting FEAT have no extensions
+	if code != 211 {
+		return nil
+	}
+	for _, line := range strings.Split(message, "\n") {
+		// the first and the last lines are not features
+		if !strings.HasPrefix(line, " ") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		params := ""
+		if len(parts) == 2 {
+			params = parts[1]
+		}
+		c.features[strings.ToUpper(parts[0])] = params
+	}
+	return nil
+}
+
+// hasFeature returns true if the FTP server supports the extension.
+func (c *controlConn) hasFeature(name string) bool {
+	_, ok := c.features[name]
+	return ok
+}
+
+func (c *controlConn) close() {
+	if c.conn != nil {
+		_, _ = c.conn.Cmd("QUIT")
+		_ = c.conn.Close()
+	}
+}
+
+// errUnexpectedReply returns the error for a reply not matching the expected format.
+func errUnexpectedReply(command, reply string) error {
+	return fmt.Errorf("unexpected reply to %s: '%s'", command, reply)
+}
@@ -0,0 +1,164 @@
+package ftpfs
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// fakeFTPServer serves a single control connection, replying to each command as set by the replies map.
+// Commands not in the map get a 502 reply.
+func fakeFTPServer(t *testing.T, replies map[string]string) (string, int, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := make(chan []string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		received := []string{}
+		defer func() { commands <- received }()
+		r := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("220 ready\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			received = append(received, line)
+			if line == "QUIT" {
+				_, _ = conn.Write([]byte("221 bye\r\n"))
+				return
+			}
+			reply, ok := replies[line]
+			if !ok {
+				reply = "502 not implemented"
+			}
+			_, _ = conn.Write([]byte(reply + "\r\n"))
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, commands
+}
+
+func TestFTPHash(t *testing.T) {
+	is := is.New(t)
+
+	host, port, commands := fakeFTPServer(t, map[string]string{
+		"USER me":              "331 password required",
+		"PASS secret":          "230 logged in",
+		"FEAT":                 "211-Features:\r\n HASH SHA-1*;SHA-256;MD5\r\n MDTM\r\n211 End",
+		"OPTS HASH SHA-256":    "200 SHA-256",
+		"HASH /www/index.html": "213 SHA-256 0-5 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824 /www/index.html",
+	})
+
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: host, Port: port, User: "me", Password: "secret", Timeout: 5})
+	conn.SetRootFolder("/www")
+
+	algorithm, err := conn.HashAlgorithm()
+	is.NoErr(err)
+	is.Equal(HashSHA256, algorithm)
+
+	sum, err := conn.Hash("index.html")
+	is.NoErr(err)
+	is.Equal("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", sum)
+
+	conn.control.close()
+	is.Equal([]string{"USER me", "PASS secret", "FEAT", "OPTS HASH SHA-256", "HASH /www/index.html", "QUIT"}, <-commands)
+}
+
+func TestFTPHashXCRC(t *testing.T) {
+	is := is.New(t)
+
+	host, port, _ := fakeFTPServer(t, map[string]string{
+		"USER me":              "230 logged in",
+		"FEAT":                 "211-Features:\r\n XCRC\r\n211 End",
+		"XCRC /www/index.html": "250 " + strconv.FormatUint(0x0610a686, 16),
+	})
+
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: host, Port: port, User: "me", Timeout: 5})
+	conn.SetRootFolder("/www")
+
+	algorithm, err := conn.HashAlgorithm()
+	is.NoErr(err)
+	is.Equal(HashCRC32, algorithm)
+
+	sum, err := conn.Hash("index.html")
+	is.NoErr(err)
+	is.Equal("0610a686", sum)
+	conn.control.close()
+}
+
+func TestFTPHashNotSupported(t *testing.T) {
+	is := is.New(t)
+
+	host, port, _ := fakeFTPServer(t, map[string]string{
+		"USER me": "230 logged in",
+	})
+
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: host, Port: port, User: "me", Timeout: 5})
+	algorithm, err := conn.HashAlgorithm()
+	is.NoErr(err)
+	is.Equal("", algorithm)
+
+	_, err = conn.Hash("index.html")
+	is.True(err != nil)
+	conn.control.close()
+}
+
+func TestFTPHashWithoutAlgorithm(t *testing.T) {
+	is := is.New(t)
+
+	host, port, commands := fakeFTPServer(t, map[string]string{
+		"USER me":              "230 logged in",
+		"FEAT":                 "211-Features:\r\n XCRC\r\n211 End",
+		"XCRC /www/index.html": "250 0610a686",
+	})
+
+	// the control connection is opened and the algorithm negotiated by Hash
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: host, Port: port, User: "me", Timeout: 5})
+	conn.SetRootFolder("/www")
+
+	sum, err := conn.Hash("index.html")
+	is.NoErr(err)
+	is.Equal("0610a686", sum)
+
+	conn.control.close()
+	is.Equal([]string{"USER me", "FEAT", "XCRC /www/index.html", "QUIT"}, <-commands)
+}
+
+func TestFTPChmod(t *testing.T) {
+	is := is.New(t)
+
+	host, port, commands := fakeFTPServer(t, map[string]string{
+		"USER me":                         "230 logged in",
+		"SITE CHMOD 644 /www/index.html":  "200 SITE CHMOD command successful",
+		"SITE CHMOD 600 /www/secret.html": "550 permission denied",
+	})
+
+	conn := NewFTPServerConnection(&FTPConnectionConfig{Host: host, Port: port, User: "me", Timeout: 5})
+	conn.SetRootFolder("/www")
+
+	is.NoErr(conn.Chmod("index.html", 0644))
+	err := conn.Chmod("secret.html", 0600)
+	is.True(err != nil)
+	is.True(!errors.Is(err, ErrChmodNotSupported))
+	// not in the replies map, answered with 502
+	is.True(errors.Is(conn.Chmod("about.html", 0644), ErrChmodNotSupported))
+
+	conn.control.close()
+	is.Equal([]string{"USER me", "FEAT", "SITE CHMOD 644 /www/index.html", "SITE CHMOD 600 /www/secret.html", "SITE CHMOD 644 /www/about.html", "QUIT"}, <-commands)
+}
@@ -0,0 +1,250 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+// IgnoreFilename is the name of the file, within the project root, listing the gitignore-style
+// rules for the paths to be neither uploaded to nor deleted from the remote folder.
+const IgnoreFilename = ".sveltinignore"
+
+// IgnoreRule is the struct representing a single rule of an ignore file.
+type IgnoreRule struct {
+	Source  string
+	Line    int
+	Pattern string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// String returns the rule as "<source>:<line>:<pattern>", the same format used by git check-ignore.
+func (r *IgnoreRule) String() string {
+	if r.Line == 0 {
+		return fmt.Sprintf("%s:%s", r.Source, r.Pattern)
+	}
+	return fmt.Sprintf("%s:%d:%s", r.Source, r.Line, r.Pattern)
+}
+
+// IgnoreRules is the ordered list of rules matching the paths, relative to the remote folder,
+// to be ignored. As for .gitignore, the last matching rule wins and a path within an ignored
+// folder cannot be included again.
+type IgnoreRules struct {
+	rules []*IgnoreRule
+}
+
+// ParseIgnoreRules returns the rules for the lines of an ignore file. Blank lines and lines
+// starting with # are skipped, a leading ! negates the pattern, a trailing / matches folders only
+// and ** matches any number of folders.
+func ParseIgnoreRules(source string, lines []string) (*IgnoreRules, error) {
+	r := &IgnoreRules{}
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := &IgnoreRule{Source: source, Line: i + 1, Pattern: line}
+		pattern := line
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		// a pattern with a slash other than the trailing one is relative to the remote folder
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+
+		re, err := compileIgnorePattern(pattern, anchored)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern at %s:%d '%s', got error '%s'", source, rule.Line, line, err.Error())
+		}
+		rule.re = re
+		r.rules = append(r.rules, rule)
+	}
+	return r, nil
+}
+
+// LoadIgnoreFile reads the rules from the ignore file. No rules are returned if it does not exist.
+func LoadIgnoreFile(appFs afero.Fs, path string) (*IgnoreRules, error) {
+	exists, err := afero.Exists(appFs, path)
+	if err != nil || !exists {
+		return &IgnoreRules{}, err
+	}
+	file, err := appFs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ParseIgnoreRules(filepath.Base(path), lines)
+}
+
+// ExcludeRules returns the rules matching the exact names of files and folders within the
+// remote folder, as set by the --exclude flag.
+func ExcludeRules(names []string) *IgnoreRules {
+	r := &IgnoreRules{}
+	for _, name := range names {
+		name = strings.Trim(filepath.ToSlash(name), "/")
+		if name == "" {
+			continue
+		}
+		r.rules = append(r.rules, &IgnoreRule{
+			Source:  "--exclude",
+			Pattern: name,
+			re:      regexp.MustCompile("^" + regexp.QuoteMeta(name) + "$"),
+		})
+	}
+	return r
+}
+
+// Merge returns the rules followed by the other ones, which take precedence.
+func (r *IgnoreRules) Merge(other *IgnoreRules) *IgnoreRules {
+	merged := &IgnoreRules{}
+	if r != nil {
+		merged.rules = append(merged.rules, r.rules...)
+	}
+	if other != nil {
+		merged.rules = append(merged.rules, other.rules...)
+	}
+	return merged
+}
+
+// IsEmpty returns true if there are no rules.
+func (r *IgnoreRules) IsEmpty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+// Match returns the rule ignoring the path, relative to the remote folder, or nil if it is not ignored.
+func (r *IgnoreRules) Match(name string, isDir bool) *IgnoreRule {
+	if r.IsEmpty() {
+		return nil
+	}
+	name = strings.Trim(filepath.ToSlash(name), "/")
+	if name == "" || name == "." {
+		return nil
+	}
+
+	// a path within an ignored folder is ignored too
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		if rule := r.lastMatch(strings.Join(parts[:i], "/"), true); rule != nil && !rule.negate {
+			return rule
+		}
+	}
+	if rule := r.lastMatch(name, isDir); rule != nil && !rule.negate {
+		return rule
+	}
+	return nil
+}
+
+func (r *IgnoreRules) lastMatch(name string, isDir bool) *IgnoreRule {
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		rule := r.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(name) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// logKept shows, on dry run, the rule preventing the remote path from being deleted.
+func logKept(logger *yinlog.Logger, name string, rule *IgnoreRule, dryRun bool) {
+	if dryRun {
+		logger.Infof("Keeping '%s' (%s)", name, rule)
+	}
+}
+
+// compileIgnorePattern translates a gitignore-style pattern into a regular expression.
+// Not anchored patterns match at any folder level.
+func compileIgnorePattern(pattern string, anchored bool) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**") && (i == 0 || pattern[i-1] == '/') {
+				switch {
+				case i+2 == len(pattern):
+					// trailing "/**" matches everything within
+					sb.WriteString(".*")
+					i++
+					continue
+				case pattern[i+2] == '/':
+					// leading "**/" and inner "/**/" match zero or more folders
+					sb.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == 0 && i+2 < len(pattern) {
+				// a leading ] is part of the class
+				if next := strings.IndexByte(pattern[i+2:], ']'); next >= 0 {
+					end = next + 1
+				} else {
+					end = -1
+				}
+			}
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
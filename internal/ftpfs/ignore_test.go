@@ -0,0 +1,111 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	is := is.New(t)
+
+	rules, err := ParseIgnoreRules(IgnoreFilename, []string{
+		"# build artifacts",
+		"*.map",
+		".DS_Store",
+		"",
+		"/drafts/",
+		"assets/**/*.psd",
+		"logs/**",
+		"!logs/keep.txt",
+		"uploads/",
+		"!uploads/photo.jpg",
+		"\\#hash.html",
+	})
+	is.NoErr(err)
+
+	tests := []struct {
+		name    string
+		isDir   bool
+		ignored bool
+		line    int
+	}{
+		{"app.js.map", false, true, 2},
+		{"_app/immutable/app.js.map", false, true, 2},
+		{"app.js", false, false, 0},
+		{".DS_Store", false, true, 3},
+		{"posts/.DS_Store", false, true, 3},
+		{"drafts", true, true, 5},
+		{"drafts/first.html", false, true, 5},
+		{"posts/drafts/first.html", false, false, 0},
+		{"assets/logo.psd", false, true, 6},
+		{"assets/images/icons/logo.psd", false, true, 6},
+		{"assets/logo.png", false, false, 0},
+		{"logs/today.txt", false, true, 7},
+		{"logs/keep.txt", false, false, 0},
+		// a file within an ignored folder cannot be included again
+		{"uploads/photo.jpg", false, true, 9},
+		{"#hash.html", false, true, 11},
+	}
+	for _, tc := range tests {
+		rule := rules.Match(tc.name, tc.isDir)
+		is.Equal(tc.ignored, rule != nil) // tc.name
+		if rule != nil {
+			is.Equal(tc.line, rule.Line) // tc.name
+		}
+	}
+
+	is.Equal(".sveltinignore:2:*.map", rules.Match("app.js.map", false).String())
+}
+
+func TestIgnoreRulesInvalidPattern(t *testing.T) {
+	is := is.New(t)
+
+	_, err := ParseIgnoreRules(IgnoreFilename, []string{"*.map", "[z-a]"})
+	is.True(err != nil)
+	is.Equal("invalid pattern at .sveltinignore:2 '[z-a]', got error 'error parsing regexp: invalid character class range: `z-a`'", err.Error())
+}
+
+func TestExcludeRules(t *testing.T) {
+	is := is.New(t)
+
+	rules := ExcludeRules([]string{".htaccess"})
+	is.True(rules.Match(".htaccess", false) != nil)
+	is.True(rules.Match("posts/.htaccess", false) == nil)
+	is.Equal("--exclude:.htaccess", rules.Match(".htaccess", false).String())
+
+	var empty *IgnoreRules
+	is.True(empty.IsEmpty())
+	is.True(empty.Match(".htaccess", false) == nil)
+	is.True(empty.Merge(rules).Match(".htaccess", false) != nil)
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	rules, err := LoadIgnoreFile(memFS, IgnoreFilename)
+	is.NoErr(err)
+	is.True(rules.IsEmpty())
+
+	is.NoErr(afero.WriteFile(memFS, IgnoreFilename, []byte("*.map\n!keep.map\n"), 0644))
+	rules, err = LoadIgnoreFile(memFS, IgnoreFilename)
+	is.NoErr(err)
+	is.True(rules.Match("app.js.map", false) != nil)
+	is.True(rules.Match("keep.map", false) == nil)
+}
+
+func TestDeleteAllKeepRules(t *testing.T) {
+	is := is.New(t)
+
+	conn := newMemServer("/www")
+	for _, file := range []string{"index.html", ".htaccess", "uploads/photo.jpg", "posts/one.html", "posts/data.json"} {
+		is.NoErr(conn.WriteFile(file, []byte(file), false))
+	}
+	keep, err := ParseIgnoreRules(IgnoreFilename, []string{"uploads/", "*.json"})
+	is.NoErr(err)
+
+	is.NoErr(DeleteAllAction(conn, keep.Merge(ExcludeRules([]string{".htaccess"})), false).Run())
+	is.Equal([]string{".htaccess", "posts/data.json", "uploads/photo.jpg"}, conn.files("/www"))
+}
@@ -0,0 +1,79 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// DirUsage is the struct representing the disk usage of a folder within the remote one.
+type DirUsage struct {
+	Path  string
+	Size  int64
+	Files int
+}
+
+// FilterRemoteFiles returns the files matching the path, relative to the remote folder,
+// or within it when it is a folder. An empty path matches all the files.
+func FilterRemoteFiles(files []RemoteFile, name string) []RemoteFile {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	filtered := []RemoteFile{}
+	for _, f := range files {
+		if name == "" || f.Path == name || strings.HasPrefix(f.Path, name+"/") {
+			filtered = append(filtered, f)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Path < filtered[j].Path
+	})
+	return filtered
+}
+
+// DiskUsage returns the size and the number of files for the folder, relative to the remote one,
+// and its subfolders up to depth levels below it. Sizes include the files within nested subfolders.
+func DiskUsage(files []RemoteFile, root string, depth int) []DirUsage {
+	root = strings.Trim(path.Clean("/"+root), "/")
+	usage := map[string]*DirUsage{}
+	add := func(dir string, f RemoteFile) {
+		u, exists := usage[dir]
+		if !exists {
+			u = &DirUsage{Path: dir}
+			usage[dir] = u
+		}
+		u.Size += f.Size
+		u.Files++
+	}
+
+	for _, f := range FilterRemoteFiles(files, root) {
+		rel := strings.TrimPrefix(strings.TrimPrefix(f.Path, root), "/")
+		dirs := strings.Split(rel, "/")
+		dirs = dirs[:len(dirs)-1]
+		add(displayDir(root), f)
+		for i := 1; i <= len(dirs) && i <= depth; i++ {
+			add(path.Join(root, strings.Join(dirs[:i], "/")), f)
+		}
+	}
+
+	result := make([]DirUsage, 0, len(usage))
+	for _, u := range usage {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+func displayDir(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
@@ -0,0 +1,54 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestDiskUsage(t *testing.T) {
+	is := is.New(t)
+
+	files := []RemoteFile{
+		{Path: "index.html", Size: 100},
+		{Path: "posts/one/index.html", Size: 10},
+		{Path: "posts/two/index.html", Size: 20},
+		{Path: "postscript.html", Size: 5},
+		{Path: "_app/app.js", Size: 1000},
+	}
+
+	is.Equal(2, len(FilterRemoteFiles(files, "posts/")))
+	is.Equal(1, len(FilterRemoteFiles(files, "/index.html")))
+	is.Equal(5, len(FilterRemoteFiles(files, "")))
+
+	is.Equal([]DirUsage{
+		{Path: ".", Size: 1135, Files: 5},
+		{Path: "_app", Size: 1000, Files: 1},
+		{Path: "posts", Size: 30, Files: 2},
+	}, DiskUsage(files, "", 1))
+
+	is.Equal([]DirUsage{
+		{Path: "posts", Size: 30, Files: 2},
+		{Path: "posts/one", Size: 10, Files: 1},
+		{Path: "posts/two", Size: 20, Files: 1},
+	}, DiskUsage(files, "posts", 3))
+}
+
+func TestDownload(t *testing.T) {
+	is := is.New(t)
+
+	conn := newMemServer("/www")
+	is.NoErr(conn.WriteFile("index.html", []byte("home"), false))
+	is.NoErr(conn.WriteFile("posts/one/index.html", []byte("one"), false))
+
+	var files []RemoteFile
+	is.NoErr(ListFilesAction(conn, &files).Run())
+	is.Equal(2, len(files))
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(DownloadAction(conn, memFS, FilterRemoteFiles(files, "posts/one"), "posts", "downloads").Run())
+	data, err := afero.ReadFile(memFS, "downloads/one/index.html")
+	is.NoErr(err)
+	is.Equal("one", string(data))
+}
@@ -0,0 +1,145 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// JournalFilename is the name of the local file keeping track of the files
+// already uploaded by a deploy, used to resume it when interrupted.
+const JournalFilename = "deploy.journal"
+
+const journalEnvPrefix = "# environment: "
+
+// Journal is the struct representing the progress of a deploy.
+// Each uploaded file is appended as a "<checksum> <path>" line.
+type Journal struct {
+	appFs     afero.Fs
+	path      string
+	env       string
+	manifest  Manifest
+	confirmed Manifest
+	mu        sync.Mutex
+}
+
+// NewJournal creates a new, empty, journal file for the files described by the manifest.
+func NewJournal(appFs afero.Fs, path, env string, manifest Manifest) (*Journal, error) {
+	if err := appFs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := afero.WriteFile(appFs, path, []byte(journalEnvPrefix+env+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("could not create the deploy journal '%s', got error '%s'", path, err.Error())
+	}
+	return &Journal{
+		appFs:     appFs,
+		path:      path,
+		env:       env,
+		manifest:  manifest,
+		confirmed: Manifest{},
+	}, nil
+}
+
+// LoadJournal reads the journal file left by an interrupted deploy.
+func LoadJournal(appFs afero.Fs, path string, manifest Manifest) (*Journal, error) {
+	file, err := appFs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the deploy journal '%s', got error '%s'", path, err.Error())
+	}
+	defer file.Close()
+
+	j := &Journal{
+		appFs:     appFs,
+		path:      path,
+		manifest:  manifest,
+		confirmed: Manifest{},
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, journalEnvPrefix) {
+			j.env = strings.TrimPrefix(line, journalEnvPrefix)
+			continue
+		}
+		// a partially written last line is just ignored
+		if parts := strings.SplitN(line, " ", 2); len(parts) == 2 && len(parts[0]) == 64 {
+			j.confirmed[parts[1]] = parts[0]
+		}
+	}
+	return j, scanner.Err()
+}
+
+// Environment returns the name of the deploy environment the journal refers to.
+func (j *Journal) Environment() string {
+	return j.env
+}
+
+// Record appends the uploaded file, as path relative to the remote folder, to the journal.
+func (j *Journal) Record(file string) error {
+	if j == nil {
+		return nil
+	}
+	file = filepath.ToSlash(file)
+	sum, exists := j.manifest[file]
+	if !exists {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f, err := j.appFs.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "%s %s\n", sum, file); err != nil {
+		return err
+	}
+	j.confirmed[file] = sum
+	return nil
+}
+
+// IsConfirmed returns true if the file has already been uploaded with the same content.
+func (j *Journal) IsConfirmed(file string) bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	sum, exists := j.confirmed[file]
+	return exists && sum == j.manifest[file]
+}
+
+// Confirmed returns the manifest of the files already uploaded.
+func (j *Journal) Confirmed() Manifest {
+	if j == nil {
+		return Manifest{}
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	m := make(Manifest, len(j.confirmed))
+	for file, sum := range j.confirmed {
+		m[file] = sum
+	}
+	return m
+}
+
+// Remove deletes the journal file once the deploy is completed.
+func (j *Journal) Remove() error {
+	if j == nil {
+		return nil
+	}
+	return j.appFs.Remove(j.path)
+}
@@ -0,0 +1,43 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestJournal(t *testing.T) {
+	is := is.New(t)
+
+	appFs := afero.NewMemMapFs()
+	sumA := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sumB := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	manifest := Manifest{"index.html": sumA, "posts/first.html": sumB}
+
+	journal, err := NewJournal(appFs, ".sveltin/deploy.journal", "staging", manifest)
+	is.NoErr(err)
+	is.NoErr(journal.Record("index.html"))
+	is.NoErr(journal.Record("not-in-manifest.html"))
+
+	loaded, err := LoadJournal(appFs, ".sveltin/deploy.journal", manifest)
+	is.NoErr(err)
+	is.Equal("staging", loaded.Environment())
+	is.True(loaded.IsConfirmed("index.html"))
+	is.True(!loaded.IsConfirmed("posts/first.html"))
+	is.Equal(1, len(loaded.Confirmed()))
+
+	// a file changed since the interrupted deploy must be uploaded again
+	changed, err := LoadJournal(appFs, ".sveltin/deploy.journal", Manifest{"index.html": sumB})
+	is.NoErr(err)
+	is.True(!changed.IsConfirmed("index.html"))
+
+	is.NoErr(loaded.Remove())
+	_, err = LoadJournal(appFs, ".sveltin/deploy.journal", manifest)
+	is.True(err != nil)
+
+	// a nil journal never confirms nor records files
+	var none *Journal
+	is.NoErr(none.Record("index.html"))
+	is.True(!none.IsConfirmed("index.html"))
+}
@@ -0,0 +1,102 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"time"
+)
+
+// LockFilename is the name of the file stored on the remote folder while a deploy is running.
+const LockFilename = ".sveltin-deploy.lock"
+
+// LockTTL is how long a lock is considered held. Older locks are left behind by
+// deploys which could not release them and are replaced.
+const LockTTL = time.Hour
+
+// Lock is the struct representing the deploy holding the remote folder.
+type Lock struct {
+	ID          string    `json:"id"`
+	User        string    `json:"user"`
+	Host        string    `json:"host"`
+	PID         int       `json:"pid"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// LockedError is the error returned when the remote folder is locked by another deploy.
+type LockedError struct {
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("the remote folder is locked by %s@%s since %s (%s ago). Wait for that deploy to end or use --force-unlock",
+		e.Lock.User, e.Lock.Host, e.Lock.CreatedAt.Local().Format(time.RFC1123), time.Since(e.Lock.CreatedAt).Round(time.Second))
+}
+
+// NewLock returns a new Lock for the current user and host.
+func NewLock(environment string) *Lock {
+	l := &Lock{
+		ID:          newLockID(),
+		User:        "unknown",
+		Host:        "unknown",
+		PID:         os.Getpid(),
+		Environment: environment,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if u, err := user.Current(); err == nil {
+		l.User = u.Username
+	}
+	if h, err := os.Hostname(); err == nil {
+		l.Host = h
+	}
+	return l
+}
+
+// ParseLock decodes the lock file content.
+func ParseLock(data []byte) (*Lock, error) {
+	l := &Lock{}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("could not parse the lock file '%s', got error '%s'", LockFilename, err.Error())
+	}
+	return l, nil
+}
+
+// Bytes encodes the lock as indented json.
+func (l *Lock) Bytes() ([]byte, error) {
+	return json.MarshalIndent(l, "", "  ")
+}
+
+// IsStale returns true if the lock is older than LockTTL.
+func (l *Lock) IsStale(now time.Time) bool {
+	return now.Sub(l.CreatedAt) > LockTTL
+}
+
+// withLockRule returns the keep rules preserving the lock file as well, it must
+// never be deleted while the deploy holding it is running.
+func withLockRule(keep *IgnoreRules) *IgnoreRules {
+	return keep.Merge(&IgnoreRules{rules: []*IgnoreRule{{
+		Source:  "deploy lock",
+		Pattern: LockFilename,
+		re:      regexp.MustCompile("^" + regexp.QuoteMeta(LockFilename) + "$"),
+	}}})
+}
+
+func newLockID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
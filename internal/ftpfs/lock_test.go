@@ -0,0 +1,63 @@
+package ftpfs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDeployLock(t *testing.T) {
+	is := is.New(t)
+
+	conn := newMemServer("/www")
+	first := NewLock("production")
+	second := NewLock("production")
+	is.True(first.ID != second.ID)
+
+	// dry run does not lock
+	is.NoErr(AcquireLockAction(conn, first, false, nil, true).Run())
+	_, err := conn.ReadFile(LockFilename)
+	is.True(err != nil)
+
+	is.NoErr(AcquireLockAction(conn, first, false, nil, false).Run())
+
+	// a fresh lock is not replaced
+	err = AcquireLockAction(conn, second, false, nil, false).Run()
+	var lockedErr *LockedError
+	is.True(errors.As(err, &lockedErr))
+	is.Equal(first.ID, lockedErr.Lock.ID)
+
+	// the lock is preserved on delete all
+	is.NoErr(conn.WriteFile("index.html", []byte("<h1>Hello</h1>"), false))
+	is.NoErr(DeleteAllAction(conn, nil, false).Run())
+	files, err := conn.ListFiles()
+	is.NoErr(err)
+	is.Equal(1, len(files))
+	is.Equal(LockFilename, files[0].Path)
+
+	// forced, the lock is replaced
+	var previous Lock
+	is.NoErr(AcquireLockAction(conn, second, true, &previous, false).Run())
+	is.Equal(first.ID, previous.ID)
+
+	// the lock held by another deploy is not released
+	is.NoErr(ReleaseLockAction(conn, first, false).Run())
+	_, err = conn.ReadFile(LockFilename)
+	is.NoErr(err)
+
+	is.NoErr(ReleaseLockAction(conn, second, false).Run())
+	_, err = conn.ReadFile(LockFilename)
+	is.True(err != nil)
+	// nothing to release
+	is.NoErr(ReleaseLockAction(conn, second, false).Run())
+
+	// a stale lock is replaced
+	first.CreatedAt = time.Now().Add(-2 * LockTTL)
+	data, err := first.Bytes()
+	is.NoErr(err)
+	is.NoErr(conn.WriteFile(LockFilename, data, false))
+	is.True(first.IsStale(time.Now()))
+	is.NoErr(AcquireLockAction(conn, second, false, &previous, false).Run())
+}
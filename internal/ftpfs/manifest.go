@@ -0,0 +1,142 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// ManifestFilename is the name of the file stored on the remote folder
+// to keep track of the deployed files and their checksums.
+const ManifestFilename = ".sveltin-manifest.json"
+
+// Manifest maps each deployed file path, relative to the remote folder, to its checksum.
+type Manifest map[string]string
+
+// ManifestDiff is the struct representing the changes between two manifests.
+type ManifestDiff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// IsEmpty returns true if there are no changes between the manifests.
+func (d *ManifestDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// NewManifest computes the checksums for the files map, where keys are
+// the paths on the remote folder and values are the paths to the local files.
+func NewManifest(appFs afero.Fs, files map[string]string) (Manifest, error) {
+	m := make(Manifest, len(files))
+	for remoteFile, localFile := range files {
+		sum, err := checksum(appFs, localFile)
+		if err != nil {
+			return nil, err
+		}
+		m[remoteFile] = sum
+	}
+	return m, nil
+}
+
+// ParseManifest decodes the manifest file content.
+func ParseManifest(data []byte) (Manifest, error) {
+	m := Manifest{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Bytes encodes the manifest as indented json.
+func (m Manifest) Bytes() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Diff returns the files to be added, changed and removed
+// to turn the remote manifest into the current one.
+func (m Manifest) Diff(remote Manifest) *ManifestDiff {
+	diff := &ManifestDiff{}
+	for file, sum := range m {
+		remoteSum, exists := remote[file]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, file)
+		case remoteSum != sum:
+			diff.Changed = append(diff.Changed, file)
+		}
+	}
+	for file := range remote {
+		if _, exists := m[file]; !exists {
+			diff.Removed = append(diff.Removed, file)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// MissingDirs returns the sorted list of parent folders for the files
+// not already existing on the remote folder described by the manifest.
+func (m Manifest) MissingDirs(files []string) []string {
+	existing := map[string]bool{}
+	for file := range m {
+		for _, dir := range parentDirs(file) {
+			existing[dir] = true
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, file := range files {
+		for _, dir := range parentDirs(file) {
+			if !existing[dir] {
+				missing[dir] = true
+			}
+		}
+	}
+
+	dirs := make([]string, 0, len(missing))
+	for dir := range missing {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+//=============================================================================
+
+// parentDirs returns all the ancestors of a slash separated file path, outermost first.
+func parentDirs(file string) []string {
+	dirs := []string{}
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+func checksum(appFs afero.Fs, file string) (string, error) {
+	f, err := appFs.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,91 @@
+package ftpfs
+
+import (
+	"errors"
+	"net/textproto"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/afero"
+)
+
+func TestManifest(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "build/index.html", []byte("<h1>Home</h1>"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "build/about/index.html", []byte("<h1>About</h1>"), 0644))
+	is.NoErr(afero.WriteFile(memFS, "build/posts/first/index.html", []byte("<h1>First</h1>"), 0644))
+
+	local, err := NewManifest(memFS, map[string]string{
+		"index.html":             "build/index.html",
+		"about/index.html":       "build/about/index.html",
+		"posts/first/index.html": "build/posts/first/index.html",
+	})
+	is.NoErr(err)
+	is.Equal(3, len(local))
+
+	data, err := local.Bytes()
+	is.NoErr(err)
+	parsed, err := ParseManifest(data)
+	is.NoErr(err)
+	is.True(parsed.Diff(local).IsEmpty())
+
+	remote := Manifest{
+		"index.html":       "outdated",
+		"about/index.html": local["about/index.html"],
+		"contact.html":     "removed",
+	}
+
+	diff := local.Diff(remote)
+	is.Equal([]string{"posts/first/index.html"}, diff.Added)
+	is.Equal([]string{"index.html"}, diff.Changed)
+	is.Equal([]string{"contact.html"}, diff.Removed)
+
+	is.Equal([]string{"posts", "posts/first"}, remote.MissingDirs(diff.Added))
+	is.Equal([]string{}, local.MissingDirs([]string{"about/team.html"}))
+
+	_, err = ParseManifest([]byte("not a json"))
+	is.True(err != nil)
+}
+
+// readErrorServer fails to read any file with err.
+type readErrorServer struct {
+	*memServer
+	err error
+}
+
+func (s *readErrorServer) ReadFile(string) ([]byte, error) { return nil, s.err }
+
+func TestReadManifest(t *testing.T) {
+	is := is.New(t)
+
+	server := newMemServer("/www")
+	manifest := Manifest{"index.html": "outdated"}
+	is.NoErr(ReadManifestAction(server, &manifest).Run())
+	is.True(manifest == nil)
+
+	is.NoErr(server.WriteFile(ManifestFilename, []byte(`{"index.html":"abc"}`), false))
+	is.NoErr(ReadManifestAction(server, &manifest).Run())
+	is.Equal(Manifest{"index.html": "abc"}, manifest)
+
+	// a missing manifest on the FTP and S3 servers
+	for _, err := range []error{
+		&textproto.Error{Code: 550, Msg: "No such file or directory"},
+		minio.ErrorResponse{StatusCode: 404, Code: "NoSuchKey"},
+	} {
+		manifest = Manifest{}
+		is.NoErr(ReadManifestAction(&readErrorServer{server, err}, &manifest).Run())
+		is.True(manifest == nil)
+	}
+
+	// any other error is returned
+	for _, err := range []error{
+		errors.New("i/o timeout"),
+		&textproto.Error{Code: 530, Msg: "Not logged in"},
+		minio.ErrorResponse{StatusCode: 403, Code: "AccessDenied"},
+	} {
+		is.True(ReadManifestAction(&readErrorServer{server, err}, &manifest).Run() != nil)
+	}
+}
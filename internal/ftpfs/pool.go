@@ -0,0 +1,125 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/yinlog"
+)
+
+// maxUploadRetries is the number of times a pool worker retries an upload failed on a transient error.
+const maxUploadRetries = 3
+
+// poolWorker is a single connection of the upload pool.
+type poolWorker struct {
+	upload    func(file string) error
+	reconnect func() error
+}
+
+// run uploads the file retrying with an exponential backoff and a new connection on transient
+// failures. Any other failure is returned at once.
+func (w *poolWorker) run(file string) error {
+	var err error
+	for attempt := 0; attempt <= maxUploadRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+			if err = w.reconnect(); err != nil {
+				if !isTransient(err) {
+					return fmt.Errorf("could not reconnect to upload '%s', got error '%s'", file, err.Error())
+				}
+				continue
+			}
+		}
+		if err = w.upload(file); err == nil {
+			return nil
+		}
+		if !isTransient(err) {
+			return fmt.Errorf("could not upload '%s', got error '%s'", file, err.Error())
+		}
+	}
+	return fmt.Errorf("could not upload '%s' after %d attempts, got error '%s'", file, maxUploadRetries+1, err.Error())
+}
+
+// runUploadPool spreads the files over the workers while a single progressbar
+// follows the uploads in order. It stops as soon as any worker fails.
+func runUploadPool(logger *yinlog.Logger, plain bool, files []string, workers []*poolWorker, onCompletesMsg string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	results := make(map[string]chan error, len(files))
+	for _, file := range files {
+		results[file] = make(chan error, 1)
+	}
+
+	jobs := make(chan string)
+	quit := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		for _, file := range files {
+			select {
+			case jobs <- file:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *poolWorker) {
+			defer wg.Done()
+			for file := range jobs {
+				results[file] <- w.run(file)
+			}
+		}(w)
+	}
+
+	var mu sync.Mutex
+	var poolErr error
+	uploaded := 0
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: onCompletesMsg,
+		OnProgressCmd: func(file string) tea.Cmd {
+			return func() tea.Msg {
+				err := <-results[file]
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					poolErr = err
+					return progressbar.IncrementErrMsg{Err: err}
+				}
+				uploaded++
+				return progressbar.IncrementMsg(file)
+			}
+		},
+	}
+
+	err := runSteps(logger, plain, pbConfig)
+	close(quit)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	if poolErr != nil {
+		return poolErr
+	}
+	if uploaded < len(files) {
+		return errors.New("upload interrupted before all files were uploaded")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package ftpfs
+
+import (
+	"errors"
+	"net"
+	"net/textproto"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/matryer/is"
+)
+
+func TestPoolWorkerRetries(t *testing.T) {
+	is := is.New(t)
+
+	attempts, reconnections := 0, 0
+	w := &poolWorker{
+		upload: func(file string) error {
+			attempts++
+			if attempts < 2 {
+				return &net.OpError{Op: "write", Net: "tcp", Err: syscall.ECONNRESET}
+			}
+			return nil
+		},
+		reconnect: func() error {
+			reconnections++
+			return nil
+		},
+	}
+
+	is.NoErr(w.run("index.html"))
+	is.Equal(2, attempts)
+	is.Equal(1, reconnections)
+}
+
+func TestPoolWorkerPermanentError(t *testing.T) {
+	is := is.New(t)
+
+	attempts, reconnections := 0, 0
+	w := &poolWorker{
+		upload: func(file string) error {
+			attempts++
+			return &textproto.Error{Code: ftp.StatusFileUnavailable, Msg: "Permission denied"}
+		},
+		reconnect: func() error {
+			reconnections++
+			return nil
+		},
+	}
+
+	err := w.run("index.html")
+	is.True(err != nil)
+	is.Equal(1, attempts)
+	is.Equal(0, reconnections)
+}
+
+func TestIsTransient(t *testing.T) {
+	is := is.New(t)
+
+	is.True(isTransient(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}))
+	is.True(isTransient(&textproto.Error{Code: ftp.StatusNotAvailable, Msg: "Service not available"}))
+	is.True(isTransient(net.ErrClosed))
+	is.True(!isTransient(&textproto.Error{Code: ftp.StatusFileUnavailable, Msg: "Permission denied"}))
+	is.True(!isTransient(&os.PathError{Op: "open", Path: "index.html", Err: os.ErrNotExist}))
+	is.True(!isTransient(errors.New("AccessDenied")))
+}
@@ -8,18 +8,76 @@
 package ftpfs
 
 import (
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/sftp"
 	"github.com/spf13/afero"
 )
 
 // RemoteServer is the interface defining the list of actions
 // can be performed on a RemoteServer implementation.
 type RemoteServer interface {
+	RootFolder() string
+	SetRootFolder(string)
+	SetJournal(*Journal)
 	Dial() error
 	Login() error
 	Logout() error
 	Idle() error
 	MakeDirs([]string, bool) error
 	UploadFiles(afero.Fs, string, []string, bool, bool) error
-	DeleteAll([]string, bool) error
+	DeleteAll(*IgnoreRules, bool) error
+	ListFiles() ([]RemoteFile, error)
 	DoBackup(afero.Fs, string, bool) error
+	ReadFile(string) ([]byte, error)
+	WriteFile(string, []byte, bool) error
+	DeleteFiles([]string, bool) error
+	Exists(string) (bool, error)
+	MakeDir(string, bool) error
+	Rename(string, string, bool) error
+	RemoveDir(string, bool) error
+}
+
+// isNotExist returns true if the error returned by a RemoteServer means the file does not exist:
+// os.ErrNotExist for the SFTP server, the 550 reply for the FTP one and NoSuchKey for S3.
+func isNotExist(err error) bool {
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	var ftpErr *textproto.Error
+	if errors.As(err, &ftpErr) {
+		return ftpErr.Code == ftp.StatusFileUnavailable
+	}
+	var s3Err minio.ErrorResponse
+	if errors.As(err, &s3Err) {
+		return s3Err.Code == "NoSuchKey"
+	}
+	return false
+}
+
+// isTransient returns true if the error returned by a RemoteServer may not happen again on
+// a new connection: a network failure, a closed connection or a 4xx reply for the FTP server.
+// Permanent failures (e.g. a 5xx reply, a missing local file, S3 AccessDenied) are not retried.
+func isTransient(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	if errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, sftp.ErrSSHFxNoConnection) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var ftpErr *textproto.Error
+	if errors.As(err, &ftpErr) {
+		return ftpErr.Code >= 400 && ftpErr.Code < 500
+	}
+	return false
 }
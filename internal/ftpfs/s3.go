@@ -0,0 +1,488 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/sveltin/utils"
+	"github.com/sveltinio/yinlog"
+)
+
+// Cache-Control headers set on the uploaded objects.
+const (
+	CacheControlRevalidate string = "public, max-age=0, must-revalidate"
+	CacheControlImmutable  string = "public, max-age=31536000, immutable"
+	CacheControlDefault    string = "public, max-age=86400"
+)
+
+// s3ContentTypes maps the extensions of the files generated by SvelteKit to their
+// content type, so that the result does not depend on the mime types of the host.
+var s3ContentTypes = map[string]string{
+	".html":        "text/html; charset=utf-8",
+	".css":         "text/css; charset=utf-8",
+	".js":          "application/javascript; charset=utf-8",
+	".mjs":         "application/javascript; charset=utf-8",
+	".json":        "application/json; charset=utf-8",
+	".map":         "application/json; charset=utf-8",
+	".webmanifest": "application/manifest+json; charset=utf-8",
+	".xml":         "application/xml; charset=utf-8",
+	".txt":         "text/plain; charset=utf-8",
+	".svg":         "image/svg+xml",
+	".png":         "image/png",
+	".jpg":         "image/jpeg",
+	".jpeg":        "image/jpeg",
+	".gif":         "image/gif",
+	".webp":        "image/webp",
+	".avif":        "image/avif",
+	".ico":         "image/x-icon",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".ttf":         "font/ttf",
+	".otf":         "font/otf",
+	".pdf":         "application/pdf",
+	".wasm":        "application/wasm",
+}
+
+// ContentType returns the Content-Type header for the file, derived from its extension.
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if contentType, ok := s3ContentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// CacheControl returns the Cache-Control header for the file. Pages and data files
+// are always revalidated, the SvelteKit hashed assets are cached forever.
+func CacheControl(name string) string {
+	name = filepath.ToSlash(name)
+	if strings.HasPrefix(name, "_app/immutable/") || strings.Contains(name, "/_app/immutable/") {
+		return CacheControlImmutable
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".json", ".xml", ".txt", ".webmanifest", "":
+		return CacheControlRevalidate
+	}
+	return CacheControlDefault
+}
+
+// S3ServerConnection is the struct with all is needed to deploy on an S3 compatible bucket.
+// The root folder is the prefix of the object keys within the bucket, no object is
+// read or written outside of it.
+type S3ServerConnection struct {
+	Config      S3ConnectionConfig
+	prefix      string
+	client      *minio.Client
+	transport   *http.Transport
+	logger      *yinlog.Logger
+	concurrency int
+	journal     *Journal
+	plain       bool
+}
+
+// NewS3ServerConnection returns a new S3ServerConnection struct.
+func NewS3ServerConnection(config *S3ConnectionConfig) *S3ServerConnection {
+	return &S3ServerConnection{
+		Config: S3ConnectionConfig{
+			Bucket:          config.Bucket,
+			Region:          config.Region,
+			Endpoint:        config.Endpoint,
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+			SessionToken:    config.SessionToken,
+			Timeout:         config.Timeout,
+		},
+	}
+}
+
+// RootFolder returns the prefix of the object keys within the bucket.
+func (s *S3ServerConnection) RootFolder() string {
+	return s.prefix
+}
+
+// SetRootFolder sets the prefix of the object keys within the bucket.
+func (s *S3ServerConnection) SetRootFolder(name string) {
+	s.prefix = strings.Trim(name, "/")
+}
+
+// SetLogger sets the logger used by the S3 connection.
+func (s *S3ServerConnection) SetLogger(logger *yinlog.Logger) {
+	s.logger = logger
+}
+
+// SetPlainOutput sets whether to log each step instead of rendering progress bars.
+func (s *S3ServerConnection) SetPlainOutput(plain bool) {
+	s.plain = plain
+}
+
+// SetJournal sets the journal recording the uploaded files.
+func (s *S3ServerConnection) SetJournal(journal *Journal) {
+	s.journal = journal
+}
+
+// SetConcurrency sets the number of parallel requests used to upload files.
+func (s *S3ServerConnection) SetConcurrency(n int) {
+	s.concurrency = n
+}
+
+// Dial contains the logic for the S3 receiver to handle the dial command.
+func (s *S3ServerConnection) Dial() error {
+	if s.transport == nil {
+		s.transport = newS3Transport(s.Config.Timeout)
+	}
+	client, err := newS3Client(&s.Config, s.transport)
+	if err != nil {
+		return err
+	}
+	s.client = client
+	s.logger.Infof("Connecting to the S3 bucket (%s/%s) ", client.EndpointURL().Host, s.Config.Bucket)
+	return nil
+}
+
+// Login contains the logic for the S3 receiver to handle the login command.
+// The bucket is requested to check it exists and the credentials are valid.
+func (s *S3ServerConnection) Login() error {
+	s.logger.Infof("Login (as %s)\n\n", s.Config.AccessKeyID)
+	exists, err := s.client.BucketExists(context.Background(), s.Config.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("the S3 bucket '%s' does not exist", s.Config.Bucket)
+	}
+	return nil
+}
+
+// Logout contains the logic for the S3 receiver to handle the logout command.
+func (s *S3ServerConnection) Logout() error {
+	s.logger.Info("Closing the connection to the S3 bucket")
+	s.transport.CloseIdleConnections()
+	return nil
+}
+
+// Idle contains the logic for the S3 receiver to handle the no-operation (idle) command.
+func (s *S3ServerConnection) Idle() error {
+	return nil
+}
+
+// MakeDirs contains the logic for the S3 receiver to handle the make dirs command.
+// Folders do not exist on object storages, they are implied by the object keys.
+func (s *S3ServerConnection) MakeDirs(folders []string, dryRun bool) error {
+	return nil
+}
+
+// UploadFiles contains the logic for the S3 receiver to handle the upload files command.
+func (s *S3ServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	sort.Strings(files)
+
+	upload := func(file string) error {
+		return s.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+	}
+	// the S3 client is safe for concurrent use and retries the failed requests itself
+	reconnect := func() error { return nil }
+
+	if s.concurrency > 1 && !dryRun {
+		workers := []*poolWorker{}
+		for i := 0; i < s.concurrency; i++ {
+			workers = append(workers, &poolWorker{upload: upload, reconnect: reconnect})
+		}
+		return runUploadPool(s.logger, s.plain, files, workers, fmt.Sprintf("Done! %d files uploaded", len(files)))
+	}
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded", len(files)),
+		OnProgressCmd: func(file string) tea.Cmd {
+			return stepTeaCmd(file, func() error {
+				if dryRun {
+					return nil
+				}
+				w := &poolWorker{upload: upload, reconnect: reconnect}
+				return w.run(file)
+			})
+		},
+	}
+
+	return runSteps(s.logger, s.plain, pbConfig)
+}
+
+// DeleteAll contains the logic for the S3 receiver to handle the delete all command.
+// Objects matching the keep rules are not deleted.
+func (s *S3ServerConnection) DeleteAll(keep *IgnoreRules, dryRun bool) error {
+	files, err := s.ListFiles()
+	if err != nil {
+		return err
+	}
+
+	if len(files) > 0 {
+		s.logger.Important("Deleting previous content from the S3 bucket")
+		for _, f := range files {
+			if rule := keep.Match(f.Path, false); rule != nil {
+				logKept(s.logger, f.Path, rule, dryRun)
+				continue
+			}
+			if dryRun {
+				continue
+			}
+			if err := s.deleteObject(s.folderPrefix(s.prefix) + f.Path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListFiles returns the list of the objects within the bucket prefix.
+func (s *S3ServerConnection) ListFiles() ([]RemoteFile, error) {
+	objects, err := s.listObjects(s.folderPrefix(s.prefix))
+	if err != nil {
+		return nil, err
+	}
+	files := []RemoteFile{}
+	for _, o := range objects {
+		if strings.HasSuffix(o.Key, "/") {
+			// folder placeholders created by some S3 clients
+			continue
+		}
+		files = append(files, RemoteFile{
+			Path:    strings.TrimPrefix(o.Key, s.folderPrefix(s.prefix)),
+			Size:    o.Size,
+			ModTime: o.LastModified,
+		})
+	}
+	return files, nil
+}
+
+// DoBackup contains the logic for the S3 receiver to handle the backup command.
+func (s *S3ServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
+	archiveFilename := tarballFilePath + "_" + time.Now().Format("20060102_3:4:5PM") + ".tar.gz"
+	s.logger.Infof("Reading the bucket: %s/%s", s.Config.Bucket, s.prefix)
+	files, err := s.ListFiles()
+	if err != nil {
+		return err
+	}
+
+	remoteFiles := make([]string, 0, len(files))
+	for _, f := range files {
+		remoteFiles = append(remoteFiles, f.Path)
+	}
+	remoteFiles = backupFiles(remoteFiles)
+
+	if !dryRun {
+		if len(remoteFiles) > 0 {
+			if err := createTarball(s.logger, s.plain, appFs, archiveFilename, remoteFiles, s.ReadFile, dryRun); err != nil {
+				return err
+			}
+		} else {
+			s.logger.Important("Nothing to backup on the server!")
+		}
+	}
+	return nil
+}
+
+// ReadFile contains the logic for the S3 receiver to retrieve an object from the bucket prefix.
+func (s *S3ServerConnection) ReadFile(name string) ([]byte, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(context.Background(), s.Config.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+// WriteFile contains the logic for the S3 receiver to store an object within the bucket prefix.
+func (s *S3ServerConnection) WriteFile(name string, data []byte, dryRun bool) error {
+	key, err := s.key(name)
+	if err != nil || dryRun {
+		return err
+	}
+	return s.putObject(key, name, bytes.NewReader(data), int64(len(data)))
+}
+
+// DeleteFiles contains the logic for the S3 receiver to handle the delete files command.
+func (s *S3ServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	for _, file := range files {
+		key, err := s.key(file)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := s.deleteObject(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Exists returns true if any object exists under the prefix.
+func (s *S3ServerConnection) Exists(name string) (bool, error) {
+	objects, err := s.listObjects(s.folderPrefix(name))
+	if err != nil {
+		return false, err
+	}
+	return len(objects) > 0, nil
+}
+
+// MakeDir does nothing, folders are implied by the object keys.
+func (s *S3ServerConnection) MakeDir(name string, dryRun bool) error {
+	return nil
+}
+
+// Rename is not supported, objects can not be moved on object storages.
+func (s *S3ServerConnection) Rename(from, to string, dryRun bool) error {
+	return errors.New("renaming is not supported by the S3 deploy target")
+}
+
+// RemoveDir deletes all the objects under the prefix.
+func (s *S3ServerConnection) RemoveDir(name string, dryRun bool) error {
+	objects, err := s.listObjects(s.folderPrefix(name))
+	if err != nil || dryRun {
+		return err
+	}
+	for _, o := range objects {
+		if err := s.deleteObject(o.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//=============================================================================
+
+// newS3Client returns the client for the S3 compatible service. Requests are sent to AWS S3
+// virtual-hosted-style, to the custom endpoints path-style.
+func newS3Client(config *S3ConnectionConfig, transport http.RoundTripper) (*minio.Client, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("the S3 bucket is not set")
+	}
+	region := config.Region
+	if region == "" {
+		region = DefaultS3Region
+	}
+
+	rawEndpoint := config.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || endpoint.Host == "" || strings.Trim(endpoint.Path, "/") != "" {
+		return nil, fmt.Errorf("'%s' is not a valid S3 endpoint", config.Endpoint)
+	}
+
+	return minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, config.SessionToken),
+		Secure:       endpoint.Scheme != "http",
+		Region:       region,
+		Transport:    transport,
+		BucketLookup: minio.BucketLookupAuto,
+	})
+}
+
+// newS3Transport returns the HTTP transport for the S3 client. The timeout only applies
+// to the connection, uploads of large files may take longer.
+func newS3Transport(timeout int) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: time.Duration(timeout) * time.Second}).DialContext
+	return transport
+}
+
+// key returns the object key for the file, relative to the bucket prefix.
+// Files resolving outside of the prefix are rejected.
+func (s *S3ServerConnection) key(name string) (string, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("'%s' is outside of the bucket prefix '%s'", name, s.prefix)
+	}
+	return path.Join(s.prefix, name), nil
+}
+
+// folderPrefix returns the prefix matching the objects within the folder.
+func (s *S3ServerConnection) folderPrefix(name string) string {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return ""
+	}
+	return name + "/"
+}
+
+// listObjects returns all the objects whose key starts with the prefix.
+func (s *S3ServerConnection) listObjects(prefix string) ([]minio.ObjectInfo, error) {
+	objects := []minio.ObjectInfo{}
+	for o := range s.client.ListObjects(context.Background(), s.Config.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if o.Err != nil {
+			return nil, o.Err
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+// putObject stores the object with the Content-Type and Cache-Control headers derived from the file name.
+// Large objects are uploaded in parts.
+func (s *S3ServerConnection) putObject(key, name string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(context.Background(), s.Config.Bucket, key, r, size, minio.PutObjectOptions{
+		ContentType:  ContentType(name),
+		CacheControl: CacheControl(name),
+	})
+	return err
+}
+
+func (s *S3ServerConnection) deleteObject(key string) error {
+	return s.client.RemoveObject(context.Background(), s.Config.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3ServerConnection) uploadLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	remoteFile := file
+	if replaceBasePath {
+		remoteFile = utils.ToBasePath(file, localDir)
+	}
+	key, err := s.key(remoteFile)
+	if err != nil {
+		return err
+	}
+	f, err := appFs.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := s.putObject(key, remoteFile, f, info.Size()); err != nil {
+		return err
+	}
+	return s.journal.Record(remoteFile)
+}
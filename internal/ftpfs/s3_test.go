@@ -0,0 +1,281 @@
+package ftpfs
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/yinlog"
+)
+
+const (
+	testS3Bucket    = "website"
+	testS3AccessKey = "minio"
+	testS3SecretKey = "minio-secret"
+)
+
+type fakeS3Object struct {
+	data         []byte
+	contentType  string
+	cacheControl string
+}
+
+// fakeS3 is an in-memory stand-in for an S3 compatible service, path-style only.
+// It checks the credentials of every request and pages the objects list two by two.
+type fakeS3 struct {
+	t             *testing.T
+	mu            sync.Mutex
+	objects       map[string]fakeS3Object
+	securityToken string
+}
+
+type fakeS3ListResult struct {
+	XMLName               xml.Name           `xml:"ListBucketResult"`
+	Contents              []fakeS3ListObject `xml:"Contents"`
+	IsTruncated           bool               `xml:"IsTruncated"`
+	NextContinuationToken string             `xml:"NextContinuationToken"`
+}
+
+type fakeS3ListObject struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{t: t, objects: map[string]fakeS3Object{}}
+	return f, httptest.NewTLSServer(f)
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential="+testS3AccessKey+"/") {
+		f.t.Errorf("unsigned request %s %s", r.Method, r.RequestURI)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>AccessDenied</Code><Message>access denied</Message></Error>")
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != testS3Bucket {
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method != http.MethodHead {
+			fmt.Fprint(w, "<Error><Code>NoSuchBucket</Code><Message>no such bucket</Message></Error>")
+		}
+		return
+	}
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.securityToken = r.Header.Get("X-Amz-Security-Token")
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, r.URL.Query())
+	case r.Method == http.MethodPut:
+		f.objects[key] = fakeS3Object{data: body, contentType: r.Header.Get("Content-Type"), cacheControl: r.Header.Get("Cache-Control")}
+	case r.Method == http.MethodGet:
+		o, exists := f.objects[key]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>no such key</Message></Error>")
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		_, _ = w.Write(o.data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	keys := []string{}
+	for k := range f.objects {
+		if strings.HasPrefix(k, query.Get("prefix")) && k > query.Get("continuation-token") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	result := fakeS3ListResult{}
+	if len(keys) > 2 {
+		keys = keys[:2]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[1]
+	}
+	for _, k := range keys {
+		result.Contents = append(result.Contents, fakeS3ListObject{Key: k, Size: int64(len(f.objects[k].data)), LastModified: time.Now().UTC()})
+	}
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeS3) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := []string{}
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newTestS3Connection(server *httptest.Server) *S3ServerConnection {
+	conn := NewS3ServerConnection(&S3ConnectionConfig{
+		Bucket:          testS3Bucket,
+		Endpoint:        server.URL,
+		AccessKeyID:     testS3AccessKey,
+		SecretAccessKey: testS3SecretKey,
+		Timeout:         5,
+	})
+	// trust the certificate of the test server
+	conn.transport = server.Client().Transport.(*http.Transport)
+	conn.SetLogger(yinlog.New())
+	conn.SetPlainOutput(true)
+	conn.SetRootFolder("/sites/blog/")
+	return conn
+}
+
+func TestS3Headers(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		name         string
+		contentType  string
+		cacheControl string
+	}{
+		{"index.html", "text/html; charset=utf-8", CacheControlRevalidate},
+		{"about/index.html", "text/html; charset=utf-8", CacheControlRevalidate},
+		{"sitemap.xml", "application/xml; charset=utf-8", CacheControlRevalidate},
+		{"manifest.webmanifest", "application/manifest+json; charset=utf-8", CacheControlRevalidate},
+		{"_app/immutable/start-a1b2c3.js", "application/javascript; charset=utf-8", CacheControlImmutable},
+		{"_app/immutable/assets/app.CSS", "text/css; charset=utf-8", CacheControlImmutable},
+		{"images/logo.svg", "image/svg+xml", CacheControlDefault},
+		{"fonts/inter.woff2", "font/woff2", CacheControlDefault},
+		{"data.unknownext", "application/octet-stream", CacheControlDefault},
+	}
+	for _, tc := range tests {
+		is.Equal(tc.contentType, ContentType(tc.name))
+		is.Equal(tc.cacheControl, CacheControl(tc.name))
+	}
+}
+
+func TestS3ServerConnection(t *testing.T) {
+	is := is.New(t)
+
+	fake, server := newFakeS3(t)
+	defer server.Close()
+
+	memFS := afero.NewMemMapFs()
+	files := []string{"build/index.html", "build/about us/index.html", "build/_app/immutable/app-1a2b.js", "build/favicon.png"}
+	for _, file := range files {
+		is.NoErr(afero.WriteFile(memFS, file, []byte("content of "+file), 0644))
+	}
+
+	conn := newTestS3Connection(server)
+	is.NoErr(DialAction(conn).Run())
+	is.NoErr(LoginAction(conn).Run())
+	defer func() { is.NoErr(LogoutAction(conn).Run()) }()
+
+	// dry run
+	is.NoErr(UploadAction(conn, memFS, "build", files, true, true).Run())
+	is.Equal(0, len(fake.keys()))
+
+	is.NoErr(conn.WriteFile(".htaccess", []byte("rules"), false))
+	// keys outside of the prefix are rejected
+	is.True(conn.WriteFile("../outside.html", []byte("outside"), false) != nil)
+	is.True(conn.WriteFile("about/../../outside.html", []byte("outside"), false) != nil)
+	is.True(conn.DeleteFiles([]string{"../outside.html"}, false) != nil)
+	_, err := conn.ReadFile("../outside.html")
+	is.True(err != nil)
+	fake.objects["sites/outside.html"] = fakeS3Object{data: []byte("outside")}
+
+	is.NoErr(UploadAction(conn, memFS, "build", files, true, false).Run())
+	is.Equal([]string{
+		"sites/blog/.htaccess",
+		"sites/blog/_app/immutable/app-1a2b.js",
+		"sites/blog/about us/index.html",
+		"sites/blog/favicon.png",
+		"sites/blog/index.html",
+		"sites/outside.html",
+	}, fake.keys())
+	is.Equal("text/html; charset=utf-8", fake.objects["sites/blog/about us/index.html"].contentType)
+	is.Equal(CacheControlImmutable, fake.objects["sites/blog/_app/immutable/app-1a2b.js"].cacheControl)
+
+	// listing is paginated by the fake server
+	var remoteFiles []RemoteFile
+	is.NoErr(ListFilesAction(conn, &remoteFiles).Run())
+	is.Equal(5, len(remoteFiles))
+
+	data, err := conn.ReadFile("about us/index.html")
+	is.NoErr(err)
+	is.Equal("content of build/about us/index.html", string(data))
+	_, err = conn.ReadFile("missing.html")
+	is.True(isNotExist(err))
+
+	// stale keys
+	is.NoErr(DeleteFilesAction(conn, []string{"favicon.png"}, true).Run())
+	is.Equal(6, len(fake.keys()))
+	is.NoErr(DeleteFilesAction(conn, []string{"favicon.png"}, false).Run())
+	is.Equal(5, len(fake.keys()))
+
+	// full deploy, kept and outside the prefix objects are preserved
+	keep := ExcludeRules([]string{".htaccess"})
+	is.NoErr(DeleteAllAction(conn, keep, true).Run())
+	is.Equal(5, len(fake.keys()))
+	is.NoErr(DeleteAllAction(conn, keep, false).Run())
+	is.Equal([]string{"sites/blog/.htaccess", "sites/outside.html"}, fake.keys())
+
+	is.True(conn.Rename("a", "b", false) != nil)
+}
+
+func TestS3LoginErrors(t *testing.T) {
+	is := is.New(t)
+
+	_, server := newFakeS3(t)
+	defer server.Close()
+
+	conn := newTestS3Connection(server)
+	conn.Config.Bucket = "missing"
+	is.NoErr(conn.Dial())
+	err := conn.Login()
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "missing"))
+
+	conn.Config.Bucket = ""
+	is.True(conn.Dial() != nil)
+
+	conn.Config.Bucket = testS3Bucket
+	conn.Config.Endpoint = "https://s3.example.com/path"
+	is.True(conn.Dial() != nil)
+}
+
+func TestS3SessionToken(t *testing.T) {
+	is := is.New(t)
+
+	fake, server := newFakeS3(t)
+	defer server.Close()
+
+	conn := newTestS3Connection(server)
+	conn.Config.SessionToken = "session-token"
+	is.NoErr(conn.Dial())
+	is.NoErr(conn.Login())
+	is.Equal("session-token", fake.securityToken)
+}
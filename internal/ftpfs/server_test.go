@@ -0,0 +1,177 @@
+package ftpfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// memServer is an in-memory RemoteServer used by tests.
+type memServer struct {
+	fs           afero.Fs
+	serverFolder string
+	failRename   bool
+}
+
+func newMemServer(root string) *memServer {
+	fs := afero.NewMemMapFs()
+	_ = fs.MkdirAll(root, 0755)
+	return &memServer{fs: fs, serverFolder: root}
+}
+
+func (s *memServer) RootFolder() string                    { return s.serverFolder }
+func (s *memServer) SetRootFolder(name string)             { s.serverFolder = name }
+func (s *memServer) SetJournal(*Journal)                   {}
+func (s *memServer) Dial() error                           { return nil }
+func (s *memServer) Login() error                          { return nil }
+func (s *memServer) Logout() error                         { return nil }
+func (s *memServer) Idle() error                           { return nil }
+func (s *memServer) DoBackup(afero.Fs, string, bool) error { return nil }
+
+func (s *memServer) MakeDirs(dirs []string, dryRun bool) error {
+	for _, dir := range dirs {
+		if !dryRun {
+			if err := s.fs.MkdirAll(path.Join(s.serverFolder, dir), 0755); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memServer) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	for _, file := range files {
+		data, err := afero.ReadFile(appFs, file)
+		if err != nil {
+			return err
+		}
+		remoteFile := file
+		if replaceBasePath {
+			remoteFile, _ = filepath.Rel(localDir, file)
+		}
+		if err := s.WriteFile(remoteFile, data, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memServer) DeleteAll(keep *IgnoreRules, dryRun bool) error {
+	_, err := s.deleteTree("", keep, dryRun)
+	return err
+}
+
+func (s *memServer) deleteTree(dir string, keep *IgnoreRules, dryRun bool) (bool, error) {
+	entries, err := afero.ReadDir(s.fs, path.Join(s.serverFolder, dir))
+	if err != nil {
+		return false, err
+	}
+	kept := false
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		if keep.Match(name, entry.IsDir()) != nil {
+			kept = true
+			continue
+		}
+		if entry.IsDir() {
+			subKept, err := s.deleteTree(name, keep, dryRun)
+			if err != nil {
+				return kept, err
+			}
+			if subKept {
+				kept = true
+				continue
+			}
+		}
+		if !dryRun {
+			if err := s.fs.RemoveAll(path.Join(s.serverFolder, name)); err != nil {
+				return kept, err
+			}
+		}
+	}
+	return kept, nil
+}
+
+func (s *memServer) ListFiles() ([]RemoteFile, error) {
+	files := []RemoteFile{}
+	for _, name := range s.files(s.serverFolder) {
+		info, err := s.fs.Stat(path.Join(s.serverFolder, name))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, RemoteFile{Path: name, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+func (s *memServer) ReadFile(name string) ([]byte, error) {
+	return afero.ReadFile(s.fs, path.Join(s.serverFolder, name))
+}
+
+func (s *memServer) WriteFile(name string, data []byte, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if err := s.fs.MkdirAll(path.Dir(path.Join(s.serverFolder, name)), 0755); err != nil {
+		return err
+	}
+	return afero.WriteFile(s.fs, path.Join(s.serverFolder, name), data, 0644)
+}
+
+func (s *memServer) DeleteFiles(files []string, dryRun bool) error {
+	for _, file := range files {
+		if !dryRun {
+			if err := s.fs.Remove(path.Join(s.serverFolder, file)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memServer) Exists(name string) (bool, error) {
+	return afero.Exists(s.fs, name)
+}
+
+func (s *memServer) MakeDir(name string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.fs.Mkdir(name, 0755)
+}
+
+func (s *memServer) Rename(from, to string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if s.failRename && strings.HasSuffix(from, NextFolderSuffix) {
+		return errors.New("rename failed")
+	}
+	return s.fs.Rename(from, to)
+}
+
+func (s *memServer) RemoveDir(name string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.fs.RemoveAll(name)
+}
+
+// files returns the sorted list of files within the folder, relative to it.
+func (s *memServer) files(folder string) []string {
+	files := []string{}
+	_ = afero.Walk(s.fs, folder, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(folder, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,569 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pkg/sftp"
+	"github.com/spf13/afero"
+	"github.com/sveltinio/prompti/progressbar"
+	"github.com/sveltinio/sveltin/utils"
+	"github.com/sveltinio/yinlog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPServerConnection is the struct with all is needed to establish and act on the SFTP remote server.
+type SFTPServerConnection struct {
+	Config       SFTPConnectionConfig
+	serverFolder string
+	sshClient    *ssh.Client
+	client       *sftp.Client
+	logger       *yinlog.Logger
+	concurrency  int
+	journal      *Journal
+	plain        bool
+}
+
+// NewSFTPServerConnection returns a new SFTPServerConnection struct.
+func NewSFTPServerConnection(config *SFTPConnectionConfig) *SFTPServerConnection {
+	return &SFTPServerConnection{
+		Config: SFTPConnectionConfig{
+			Host:                  config.Host,
+			Port:                  config.Port,
+			User:                  config.User,
+			Password:              config.Password,
+			Timeout:               config.Timeout,
+			KeyPath:               config.KeyPath,
+			KeyPassphrase:         config.KeyPassphrase,
+			KnownHostsPath:        config.KnownHostsPath,
+			InsecureIgnoreHostKey: config.InsecureIgnoreHostKey,
+		},
+	}
+}
+
+// RootFolder returns the root folder on the SFTP remote server.
+func (s *SFTPServerConnection) RootFolder() string {
+	return s.serverFolder
+}
+
+// SetRootFolder sets the root folder on the SFTP remote server.
+func (s *SFTPServerConnection) SetRootFolder(name string) {
+	s.serverFolder = name
+}
+
+// SetLogger sets the logger used by the SFTP connection.
+func (s *SFTPServerConnection) SetLogger(logger *yinlog.Logger) {
+	s.logger = logger
+}
+
+// SetPlainOutput sets whether to log each step instead of rendering progress bars.
+func (s *SFTPServerConnection) SetPlainOutput(plain bool) {
+	s.plain = plain
+}
+
+// SetJournal sets the journal recording the uploaded files.
+func (s *SFTPServerConnection) SetJournal(journal *Journal) {
+	s.journal = journal
+}
+
+// SetConcurrency sets the number of connections used to upload files.
+func (s *SFTPServerConnection) SetConcurrency(n int) {
+	s.concurrency = n
+}
+
+// Dial contains the logic for the SFTP receiver to handle the dial command.
+// The SSH handshake includes the user authentication.
+func (s *SFTPServerConnection) Dial() error {
+	s.logger.Infof("Connecting to the SFTP Server (%s) ", s.Config.makeConnectionString())
+	if s.Config.InsecureIgnoreHostKey {
+		s.logger.Important("The remote host key will not be verified!")
+	}
+	return s.dial()
+}
+
+// Login contains the logic for the SFTP receiver to handle the login command.
+// It opens the SFTP session over the already authenticated SSH connection.
+func (s *SFTPServerConnection) Login() error {
+	s.logger.Infof("Login (as %s)\n\n", s.Config.User)
+	c, err := sftp.NewClient(s.sshClient)
+	if err != nil {
+		return err
+	}
+	s.client = c
+	return nil
+}
+
+// Logout contains the logic for the SFTP receiver to handle the logout command.
+func (s *SFTPServerConnection) Logout() error {
+	s.logger.Info("Closing the connection to the SFTP server")
+	if err := s.client.Close(); err != nil {
+		return err
+	}
+	return s.sshClient.Close()
+}
+
+// Idle contains the logic for the SFTP receiver to handle the no-operation (idle) command.
+func (s *SFTPServerConnection) Idle() error {
+	_, _, err := s.sshClient.SendRequest("keepalive@openssh.com", true, nil)
+	return err
+}
+
+// MakeDirs contains the logic for the SFTP receiver to handle the make dirs command.
+func (s *SFTPServerConnection) MakeDirs(folders []string, dryRun bool) error {
+	sort.Strings(folders)
+
+	pbConfig := &progressbar.Config{
+		Items:          folders,
+		OnCompletesMsg: fmt.Sprintf("Done! %d folders created", len(folders)),
+		OnProgressCmd: func(dir string) tea.Cmd {
+			return stepTeaCmd(dir, func() error {
+				if dryRun {
+					return nil
+				}
+				return s.client.MkdirAll(path.Join(s.serverFolder, filepath.ToSlash(dir)))
+			})
+		},
+	}
+
+	return runSteps(s.logger, s.plain, pbConfig)
+}
+
+// UploadFiles contains the logic for the SFTP receiver to handle the upload files command.
+func (s *SFTPServerConnection) UploadFiles(appFs afero.Fs, localDir string, files []string, replaceBasePath, dryRun bool) error {
+	sort.Strings(files)
+
+	if s.concurrency > 1 && !dryRun {
+		return s.uploadFilesConcurrently(appFs, localDir, files, replaceBasePath)
+	}
+
+	pbConfig := &progressbar.Config{
+		Items:          files,
+		OnCompletesMsg: fmt.Sprintf("Done! %d files uploaded", len(files)),
+		OnProgressCmd: func(file string) tea.Cmd {
+			return stepTeaCmd(file, func() error {
+				if dryRun {
+					return nil
+				}
+				w := &poolWorker{
+					upload: func(file string) error {
+						return s.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+					},
+					reconnect: s.reconnect,
+				}
+				return w.run(file)
+			})
+		},
+	}
+
+	return runSteps(s.logger, s.plain, pbConfig)
+}
+
+// DeleteAll contains the logic for the SFTP receiver to handle the delete all command.
+// Files and folders matching the keep rules are not deleted.
+func (s *SFTPServerConnection) DeleteAll(keep *IgnoreRules, dryrun bool) error {
+	entries, err := s.client.ReadDir(s.serverFolder)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) > 0 {
+		s.logger.Important("Deleting previous content from the SFTP remote folder")
+		if _, err := s.deleteTree("", keep, dryrun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListFiles returns the list of the files within the SFTP remote folder.
+func (s *SFTPServerConnection) ListFiles() ([]RemoteFile, error) {
+	w := s.client.Walk(s.serverFolder)
+	files := []RemoteFile{}
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return nil, err
+		}
+		if info := w.Stat(); info.Mode().IsRegular() {
+			files = append(files, RemoteFile{
+				Path:    utils.ToBasePath(w.Path(), s.serverFolder),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+		}
+	}
+	return files, nil
+}
+
+// DoBackup contains the logic for the SFTP receiver to handle the backup command.
+func (s *SFTPServerConnection) DoBackup(appFs afero.Fs, tarballFilePath string, dryRun bool) error {
+	archiveFilename := tarballFilePath + "_" + time.Now().Format("20060102_3:4:5PM") + ".tar.gz"
+	s.logger.Infof("Reading the remote folder: %s", s.serverFolder)
+	remoteFiles, err := s.walkRemote()
+	if err != nil {
+		return err
+	}
+	remoteFiles = backupFiles(remoteFiles)
+
+	if !dryRun {
+		if len(remoteFiles) > 0 {
+			if err := createTarball(s.logger, s.plain, appFs, archiveFilename, remoteFiles, s.retrieve, dryRun); err != nil {
+				return err
+			}
+		} else {
+			s.logger.Important("Nothing to backup on the server!")
+		}
+	}
+	return nil
+}
+
+// ReadFile contains the logic for the SFTP receiver to retrieve a file from the remote folder.
+func (s *SFTPServerConnection) ReadFile(name string) ([]byte, error) {
+	return s.retrieve(name)
+}
+
+// WriteFile contains the logic for the SFTP receiver to store a file on the remote folder.
+func (s *SFTPServerConnection) WriteFile(name string, data []byte, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	dst, err := s.client.Create(path.Join(s.serverFolder, filepath.ToSlash(name)))
+	if err != nil {
+		return err
+	}
+	// a failed or short write is reported by Close
+	_, err = dst.Write(data)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// DeleteFiles contains the logic for the SFTP receiver to handle the delete files command.
+func (s *SFTPServerConnection) DeleteFiles(files []string, dryRun bool) error {
+	for _, file := range files {
+		if !dryRun {
+			if err := s.client.Remove(path.Join(s.serverFolder, filepath.ToSlash(file))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Exists returns true if the path exists on the SFTP remote server.
+func (s *SFTPServerConnection) Exists(name string) (bool, error) {
+	_, err := s.client.Stat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MakeDir creates the folder on the SFTP remote server.
+func (s *SFTPServerConnection) MakeDir(name string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.client.Mkdir(name)
+}
+
+// Rename renames the path on the SFTP remote server.
+func (s *SFTPServerConnection) Rename(from, to string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return s.client.Rename(from, to)
+}
+
+// Chmod sets the permissions of the file or folder on the SFTP remote server.
+func (s *SFTPServerConnection) Chmod(name string, mode os.FileMode) error {
+	return s.client.Chmod(path.Join(s.serverFolder, filepath.ToSlash(name)), mode)
+}
+
+// RemoveDir deletes the folder and all its content from the SFTP remote server, if it exists.
+func (s *SFTPServerConnection) RemoveDir(name string, dryRun bool) error {
+	exists, err := s.Exists(name)
+	if err != nil || !exists || dryRun {
+		return err
+	}
+	return s.removeAll(name)
+}
+
+//=============================================================================
+
+func (s *SFTPServerConnection) dial() error {
+	authMethods, err := s.authMethods()
+	if err != nil {
+		return err
+	}
+
+	hostKeyCallback, err := s.hostKeyCallback()
+	if err != nil {
+		return err
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            s.Config.User,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         time.Duration(s.Config.Timeout) * time.Second,
+	}
+
+	c, err := ssh.Dial("tcp", s.Config.makeConnectionString(), sshConfig)
+	if err != nil {
+		return err
+	}
+	s.sshClient = c
+	return nil
+}
+
+// connect dials and opens the SFTP session without any output, it is used by the upload pool workers.
+func (s *SFTPServerConnection) connect() error {
+	if err := s.dial(); err != nil {
+		return err
+	}
+	c, err := sftp.NewClient(s.sshClient)
+	if err != nil {
+		return err
+	}
+	s.client = c
+	return nil
+}
+
+func (s *SFTPServerConnection) close() {
+	if s.client != nil {
+		_ = s.client.Close()
+	}
+	if s.sshClient != nil {
+		_ = s.sshClient.Close()
+	}
+}
+
+// reconnect replaces the connection to the SFTP server with a new logged-in one.
+func (s *SFTPServerConnection) reconnect() error {
+	s.close()
+	return s.connect()
+}
+
+// uploadFilesConcurrently spreads the files upload over a pool of SFTP connections.
+func (s *SFTPServerConnection) uploadFilesConcurrently(appFs afero.Fs, localDir string, files []string, replaceBasePath bool) error {
+	s.logger.Infof("Opening %d connections to the SFTP server", s.concurrency)
+	conns := []*SFTPServerConnection{}
+	defer func() {
+		for _, c := range conns {
+			c.close()
+		}
+	}()
+
+	workers := []*poolWorker{}
+	for i := 0; i < s.concurrency; i++ {
+		c := NewSFTPServerConnection(&s.Config)
+		c.SetRootFolder(s.serverFolder)
+		c.SetJournal(s.journal)
+		if err := c.connect(); err != nil {
+			return err
+		}
+		conns = append(conns, c)
+		workers = append(workers, &poolWorker{
+			upload: func(file string) error {
+				return c.uploadLocalFile(appFs, file, localDir, replaceBasePath)
+			},
+			reconnect: c.reconnect,
+		})
+	}
+
+	return runUploadPool(s.logger, s.plain, files, workers, fmt.Sprintf("Done! %d files uploaded", len(files)))
+}
+
+func (s *SFTPServerConnection) uploadLocalFile(appFs afero.Fs, file, localDir string, replaceBasePath bool) error {
+	remoteFile := file
+	if replaceBasePath {
+		remoteFile = utils.ToBasePath(file, localDir)
+	}
+	if err := s.uploadSingle(appFs, file, remoteFile); err != nil {
+		return err
+	}
+	return s.journal.Record(remoteFile)
+}
+
+func (s *SFTPServerConnection) authMethods() ([]ssh.AuthMethod, error) {
+	methods := []ssh.AuthMethod{}
+	if s.Config.KeyPath != "" {
+		keyBytes, err := os.ReadFile(expandHomeDir(s.Config.KeyPath))
+		if err != nil {
+			return nil, fmt.Errorf("could not read the SSH private key '%s', got error '%s'", s.Config.KeyPath, err.Error())
+		}
+
+		var signer ssh.Signer
+		if s.Config.KeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(s.Config.KeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(keyBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the SSH private key '%s', got error '%s'", s.Config.KeyPath, err.Error())
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if s.Config.Password != "" {
+		methods = append(methods, ssh.Password(s.Config.Password))
+	}
+
+	if len(methods) == 0 {
+		return nil, errors.New("no SSH authentication method available. Set either SSH_KEY_PATH or the FTP password (see sveltin secrets --help)")
+	}
+	return methods, nil
+}
+
+func (s *SFTPServerConnection) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if s.Config.InsecureIgnoreHostKey {
+		// #nosec G106 -- explicitly requested by the user with SSH_INSECURE_IGNORE_HOST_KEY
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	knownHostsPath := s.Config.KnownHostsPath
+	if knownHostsPath == "" {
+		knownHostsPath = "~/.ssh/known_hosts"
+	}
+
+	callback, err := knownhosts.New(expandHomeDir(knownHostsPath))
+	if err != nil {
+		return nil, fmt.Errorf("could not load the known hosts file '%s', got error '%s'", knownHostsPath, err.Error())
+	}
+	return callback, nil
+}
+
+// deleteTree deletes the content of the folder, relative to the remote one, except the paths
+// matching the keep rules. It returns true if something has been kept.
+func (s *SFTPServerConnection) deleteTree(dir string, keep *IgnoreRules, dryRun bool) (bool, error) {
+	entries, err := s.client.ReadDir(path.Join(s.serverFolder, dir))
+	if err != nil {
+		return false, err
+	}
+
+	kept := false
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		if rule := keep.Match(name, entry.IsDir()); rule != nil {
+			logKept(s.logger, name, rule, dryRun)
+			kept = true
+			continue
+		}
+
+		remotePath := path.Join(s.serverFolder, name)
+		switch {
+		case dryRun && (!entry.IsDir() || keep.IsEmpty()):
+		case entry.IsDir() && !keep.IsEmpty():
+			// rules may match paths within the folder
+			subKept, err := s.deleteTree(name, keep, dryRun)
+			if err != nil {
+				return kept, err
+			}
+			if subKept {
+				kept = true
+			} else if !dryRun {
+				if err := s.client.RemoveDirectory(remotePath); err != nil {
+					return kept, err
+				}
+			}
+		default:
+			if err := s.removeAll(remotePath); err != nil {
+				return kept, err
+			}
+		}
+	}
+	return kept, nil
+}
+
+func (s *SFTPServerConnection) walkRemote() ([]string, error) {
+	w := s.client.Walk(s.serverFolder)
+	var remoteFiles []string
+	for w.Step() {
+		if err := w.Err(); err != nil {
+			return nil, err
+		}
+		if w.Stat().Mode().IsRegular() {
+			remoteFiles = append(remoteFiles, utils.ToBasePath(w.Path(), s.serverFolder))
+		}
+	}
+	return remoteFiles, nil
+}
+
+func (s *SFTPServerConnection) retrieve(file string) ([]byte, error) {
+	r, err := s.client.Open(path.Join(s.serverFolder, filepath.ToSlash(file)))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (s *SFTPServerConnection) uploadSingle(appFs afero.Fs, localFile, remoteFile string) error {
+	src, err := appFs.Open(localFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := s.client.Create(path.Join(s.serverFolder, filepath.ToSlash(remoteFile)))
+	if err != nil {
+		return err
+	}
+	_, err = dst.ReadFrom(src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// removeAll deletes the remote path and, when it is a folder, all its content.
+func (s *SFTPServerConnection) removeAll(remotePath string) error {
+	info, err := s.client.Stat(remotePath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return s.client.Remove(remotePath)
+	}
+
+	entries, err := s.client.ReadDir(remotePath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := s.removeAll(path.Join(remotePath, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return s.client.RemoveDirectory(remotePath)
+}
+
+// expandHomeDir replaces the leading ~ with the user home directory.
+func expandHomeDir(p string) string {
+	if !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[2:])
+}
@@ -0,0 +1,108 @@
+package ftpfs
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/pkg/sftp"
+	"github.com/spf13/afero"
+)
+
+// newTestSFTPConnection returns a connection to an in-memory SFTP server serving the handlers.
+func newTestSFTPConnection(t *testing.T, handlers sftp.Handlers) *SFTPServerConnection {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server := sftp.NewRequestServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter}, handlers)
+	go func() { _ = server.Serve() }()
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+
+	conn := NewSFTPServerConnection(&SFTPConnectionConfig{Host: "localhost", Port: 22, User: "me"})
+	conn.client = client
+	conn.SetRootFolder("/www")
+	if err := client.MkdirAll("/www"); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestSFTPReadWriteFile(t *testing.T) {
+	is := is.New(t)
+	conn := newTestSFTPConnection(t, sftp.InMemHandler())
+
+	is.NoErr(conn.WriteFile("index.html", []byte("<h1>Hello</h1>"), false))
+	data, err := conn.ReadFile("index.html")
+	is.NoErr(err)
+	is.Equal("<h1>Hello</h1>", string(data))
+
+	// nothing is written on dry run
+	is.NoErr(conn.WriteFile("dry.html", []byte("dry"), true))
+	exists, err := conn.Exists("/www/dry.html")
+	is.NoErr(err)
+	is.True(!exists)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "build/about.html", []byte("<h1>About</h1>"), 0644))
+	is.NoErr(conn.uploadSingle(memFS, "build/about.html", "about.html"))
+	data, err = conn.ReadFile("about.html")
+	is.NoErr(err)
+	is.Equal("<h1>About</h1>", string(data))
+}
+
+func TestSFTPFolders(t *testing.T) {
+	is := is.New(t)
+	conn := newTestSFTPConnection(t, sftp.InMemHandler())
+
+	is.NoErr(conn.MakeDir("/www/_app", false))
+	is.NoErr(conn.WriteFile("_app/app.js", []byte("app"), false))
+	is.NoErr(conn.Rename("/www/_app", "/www/_next", false))
+
+	exists, err := conn.Exists("/www/_app")
+	is.NoErr(err)
+	is.True(!exists)
+	data, err := conn.ReadFile("_next/app.js")
+	is.NoErr(err)
+	is.Equal("app", string(data))
+
+	is.NoErr(conn.RemoveDir("/www/_next", false))
+	exists, err = conn.Exists("/www/_next")
+	is.NoErr(err)
+	is.True(!exists)
+	// a missing folder is not an error
+	is.NoErr(conn.RemoveDir("/www/_next", false))
+}
+
+// failingCloseWriter accepts the writes but fails on close, as a server does on a short write.
+type failingCloseWriter struct{}
+
+func (failingCloseWriter) Filewrite(*sftp.Request) (io.WriterAt, error) {
+	return failingCloseWriter{}, nil
+}
+func (failingCloseWriter) WriteAt(p []byte, off int64) (int, error) { return len(p), nil }
+func (failingCloseWriter) Close() error                             { return errors.New("disk full") }
+
+func TestSFTPWriteFileCloseError(t *testing.T) {
+	is := is.New(t)
+
+	handlers := sftp.InMemHandler()
+	handlers.FilePut = failingCloseWriter{}
+	conn := newTestSFTPConnection(t, handlers)
+
+	is.True(conn.WriteFile("index.html", []byte("<h1>Hello</h1>"), false) != nil)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "build/about.html", []byte("<h1>About</h1>"), 0644))
+	is.True(conn.uploadSingle(memFS, "build/about.html", "about.html") != nil)
+}
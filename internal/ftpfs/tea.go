@@ -2,19 +2,18 @@ package ftpfs
 
 import (
 	"archive/tar"
-	"bytes"
-	"io"
+	"errors"
 	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/afero"
 	"github.com/sveltinio/prompti/progressbar"
-	"github.com/sveltinio/sveltin/utils"
+	"github.com/sveltinio/yinlog"
 )
 
 func mkDirTeaCmd(s *FTPServerConnection, path string, dryRun bool) tea.Cmd {
 	if !dryRun {
-		if err := s.client.MakeDir(path); err != nil {
+		if err := s.client.MakeDir(path); err != nil && !s.isDir(path) {
 			return func() tea.Msg {
 				return progressbar.IncrementErrMsg{Err: err}
 			}
@@ -28,58 +27,32 @@ func mkDirTeaCmd(s *FTPServerConnection, path string, dryRun bool) tea.Cmd {
 
 func uploadFileTeaCmd(s *FTPServerConnection, appFs afero.Fs, file, path string, replaceBasePath, dryRun bool) tea.Cmd {
 	if !dryRun {
-		fileBytes, err := afero.ReadFile(appFs, file)
-		if err != nil {
-			return func() tea.Msg {
-				return progressbar.IncrementErrMsg{Err: err}
-			}
-		}
-		var remoteFile string
-		if replaceBasePath {
-			remoteFile = utils.ToBasePath(file, path)
-		} else {
-			remoteFile = file
+		w := &poolWorker{
+			upload: func(file string) error {
+				return s.uploadLocalFile(appFs, file, path, replaceBasePath)
+			},
+			reconnect: s.reconnect,
 		}
-
-		if err = s.uploadSingle(remoteFile, bytes.NewBuffer(fileBytes), dryRun); err != nil {
+		if err := w.run(file); err != nil {
 			return func() tea.Msg {
 				return progressbar.IncrementErrMsg{Err: err}
 			}
 		}
-
 	}
 	return func() tea.Msg {
 		return progressbar.IncrementMsg(path)
 	}
 }
 
-func createTarballTeaCmd(s *FTPServerConnection, memFs afero.Fs, tarWriter *tar.Writer, file string, dryRun bool) tea.Cmd {
-	fPath := filepath.Dir(file)
-	fName := filepath.Base(file)
-
-	if err := s.client.ChangeDir(filepath.Join(s.serverFolder, fPath)); err != nil {
-		return func() tea.Msg {
-			return progressbar.IncrementErrMsg{Err: err}
-		}
-	}
-
+func createTarballTeaCmd(memFs afero.Fs, tarWriter *tar.Writer, file string, fetch fetchFunc, dryRun bool) tea.Cmd {
 	if !dryRun {
-		// fetch the file from the remote FTP server
-		r, err := s.client.Retr(fName)
-		if err != nil {
-			return func() tea.Msg {
-				return progressbar.IncrementErrMsg{Err: err}
-			}
-		}
-		defer r.Close()
-		// retrieve the file content
-		buf, err := io.ReadAll(r)
+		// fetch the file content from the remote server
+		buf, err := fetch(file)
 		if err != nil {
 			return func() tea.Msg {
 				return progressbar.IncrementErrMsg{Err: err}
 			}
 		}
-		r.Close()
 		// save file in the memory backed filesystem
 		if err := afero.WriteFile(memFs, file, buf, 0777); err != nil {
 			return func() tea.Msg {
@@ -95,6 +68,68 @@ func createTarballTeaCmd(s *FTPServerConnection, memFs afero.Fs, tarWriter *tar.
 	}
 
 	return func() tea.Msg {
-		return progressbar.IncrementMsg(fName)
+		return progressbar.IncrementMsg(filepath.Base(file))
+	}
+}
+
+// stepTeaCmd runs a single step of a progressbar driven action and
+// reports its outcome back to the progressbar.
+func stepTeaCmd(item string, step func() error) tea.Cmd {
+	if err := step(); err != nil {
+		return func() tea.Msg {
+			return progressbar.IncrementErrMsg{Err: err}
+		}
+	}
+
+	return func() tea.Msg {
+		return progressbar.IncrementMsg(item)
+	}
+}
+
+// runSteps runs the progressbar steps. In plain mode, as when not running in a terminal,
+// no progressbar is rendered and each completed step is logged instead.
+func runSteps(logger *yinlog.Logger, plain bool, pbConfig *progressbar.Config) error {
+	if !plain {
+		return runProgressbar(pbConfig)
+	}
+	for _, item := range pbConfig.Items {
+		if msg, ok := pbConfig.OnProgressCmd(item)().(progressbar.IncrementErrMsg); ok {
+			return msg.Err
+		}
+		logger.Plainf("  %s", item)
+	}
+	logger.Success(pbConfig.OnCompletesMsg)
+	return nil
+}
+
+// runProgressbar runs the progressbar and returns the error reported by any of
+// its steps, or an error if it has been interrupted before completing all of them.
+func runProgressbar(pbConfig *progressbar.Config) error {
+	var stepErr error
+	completed := 0
+	onProgressCmd := pbConfig.OnProgressCmd
+	pbConfig.OnProgressCmd = func(item string) tea.Cmd {
+		cmd := onProgressCmd(item)
+		return func() tea.Msg {
+			msg := cmd()
+			switch msg := msg.(type) {
+			case progressbar.IncrementErrMsg:
+				stepErr = msg.Err
+			case progressbar.IncrementMsg:
+				completed++
+			}
+			return msg
+		}
+	}
+
+	if _, err := progressbar.Run(pbConfig); err != nil {
+		return err
+	}
+	if stepErr != nil {
+		return stepErr
+	}
+	if completed < len(pbConfig.Items) {
+		return errors.New("interrupted before completing all the steps")
 	}
+	return nil
 }
@@ -0,0 +1,168 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package ftpfs
+
+import (
+	"crypto/md5"  // #nosec G501 -- MD5 is only used when it is the only checksum supported by the server
+	"crypto/sha1" // #nosec G505 -- SHA-1 is only used when it is the only checksum supported by the server
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Checksum algorithms used to verify the remote files.
+const (
+	HashSHA256 string = "SHA-256"
+	HashSHA1   string = "SHA-1"
+	HashMD5    string = "MD5"
+	HashCRC32  string = "CRC32"
+)
+
+// RemoteFile is the struct representing a file within the remote folder.
+type RemoteFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Hasher is implemented by the remote servers able to compute the checksum of the remote files.
+type Hasher interface {
+	// HashAlgorithm returns the checksum algorithm supported by the remote server, empty if none.
+	HashAlgorithm() (string, error)
+	// Hash returns the hex encoded checksum of the file, relative to the remote folder.
+	Hash(name string) (string, error)
+}
+
+// VerifyResult is the struct representing the outcome of the comparison between
+// the local build and the remote folder.
+type VerifyResult struct {
+	Algorithm  string
+	Checked    int
+	Missing    []string
+	Extra      []string
+	Mismatched []VerifyMismatch
+}
+
+// VerifyMismatch is the struct representing a file whose remote copy does not match the local one.
+type VerifyMismatch struct {
+	Path   string
+	Reason string
+}
+
+// IsValid returns true if the remote folder matches the local build.
+func (r *VerifyResult) IsValid() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+// verify compares the local files, keyed by their path on the remote folder, with the remote ones.
+// Remote files matching the keep rules, the manifest and the lock are not reported as extra.
+func verify(server RemoteServer, appFs afero.Fs, files map[string]string, keep *IgnoreRules) (*VerifyResult, error) {
+	remoteFiles, err := server.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{Missing: []string{}, Extra: []string{}, Mismatched: []VerifyMismatch{}}
+	var hasher Hasher
+	if h, ok := server.(Hasher); ok {
+		if result.Algorithm, err = h.HashAlgorithm(); err != nil {
+			return nil, err
+		}
+		if result.Algorithm != "" {
+			hasher = h
+		}
+	}
+
+	remoteSizes := make(map[string]int64, len(remoteFiles))
+	for _, f := range remoteFiles {
+		remoteSizes[f.Path] = f.Size
+		if _, exists := files[f.Path]; !exists && f.Path != ManifestFilename && f.Path != LockFilename && keep.Match(f.Path, false) == nil {
+			result.Extra = append(result.Extra, f.Path)
+		}
+	}
+
+	for remotePath, localPath := range files {
+		remoteSize, exists := remoteSizes[remotePath]
+		if !exists {
+			result.Missing = append(result.Missing, remotePath)
+			continue
+		}
+		result.Checked++
+
+		info, err := appFs.Stat(localPath)
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() != remoteSize {
+			result.Mismatched = append(result.Mismatched, VerifyMismatch{
+				Path:   remotePath,
+				Reason: fmt.Sprintf("size %d, expected %d", remoteSize, info.Size()),
+			})
+			continue
+		}
+
+		if hasher == nil {
+			continue
+		}
+		remoteSum, err := hasher.Hash(remotePath)
+		if err != nil {
+			return nil, err
+		}
+		localSum, err := localChecksum(appFs, localPath, result.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if remoteSum != localSum {
+			result.Mismatched = append(result.Mismatched, VerifyMismatch{
+				Path:   remotePath,
+				Reason: fmt.Sprintf("%s %s, expected %s", result.Algorithm, remoteSum, localSum),
+			})
+		}
+	}
+
+	sort.Strings(result.Missing)
+	sort.Strings(result.Extra)
+	sort.Slice(result.Mismatched, func(i, j int) bool {
+		return result.Mismatched[i].Path < result.Mismatched[j].Path
+	})
+	return result, nil
+}
+
+// localChecksum returns the hex encoded checksum of the local file for the algorithm.
+func localChecksum(appFs afero.Fs, file, algorithm string) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case HashSHA256:
+		h = sha256.New()
+	case HashSHA1:
+		h = sha1.New()
+	case HashMD5:
+		h = md5.New()
+	case HashCRC32:
+		h = crc32.NewIEEE()
+	default:
+		return "", fmt.Errorf("checksum algorithm '%s' not supported", algorithm)
+	}
+
+	f, err := appFs.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
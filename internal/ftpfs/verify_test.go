@@ -0,0 +1,86 @@
+package ftpfs
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+// hashingServer is a memServer computing the SHA-256 checksum of the remote files.
+type hashingServer struct {
+	*memServer
+}
+
+func (s *hashingServer) HashAlgorithm() (string, error) { return HashSHA256, nil }
+
+func (s *hashingServer) Hash(name string) (string, error) {
+	return localChecksum(s.fs, s.serverFolder+"/"+name, HashSHA256)
+}
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	files := map[string]string{
+		"index.html":       "build/index.html",
+		"about/index.html": "build/about/index.html",
+		"app.js":           "build/app.js",
+		"missing.html":     "build/missing.html",
+	}
+	for _, file := range files {
+		is.NoErr(afero.WriteFile(memFS, file, []byte("<h1>"+file+"</h1>"), 0644))
+	}
+
+	conn := newMemServer("/www")
+	is.NoErr(conn.WriteFile("index.html", []byte("<h1>build/index.html</h1>"), false))
+	is.NoErr(conn.WriteFile("about/index.html", []byte("<h1>build/about/index.html</h1>"), false))
+	// same size, different content
+	is.NoErr(conn.WriteFile("app.js", []byte("<h1>build/app.jz</h1>"), false))
+	is.NoErr(conn.WriteFile("old.html", []byte("old"), false))
+	is.NoErr(conn.WriteFile(".htaccess", []byte("rules"), false))
+	is.NoErr(conn.WriteFile(ManifestFilename, []byte("{}"), false))
+	keep := ExcludeRules([]string{".htaccess"})
+
+	// sizes only
+	var result VerifyResult
+	is.NoErr(VerifyAction(conn, memFS, files, keep, &result).Run())
+	is.Equal("", result.Algorithm)
+	is.Equal(3, result.Checked)
+	is.Equal([]string{"missing.html"}, result.Missing)
+	is.Equal([]string{"old.html"}, result.Extra)
+	is.Equal(0, len(result.Mismatched))
+	is.True(!result.IsValid())
+
+	// sizes and checksums
+	is.NoErr(VerifyAction(&hashingServer{conn}, memFS, files, keep, &result).Run())
+	is.Equal(HashSHA256, result.Algorithm)
+	is.Equal(1, len(result.Mismatched))
+	is.Equal("app.js", result.Mismatched[0].Path)
+
+	is.NoErr(conn.WriteFile("app.js", []byte("<h1>build/app.js</h1>"), false))
+	is.NoErr(conn.WriteFile("missing.html", []byte("<h1>build/missing.html</h1>"), false))
+	is.NoErr(conn.DeleteFiles([]string{"old.html"}, false))
+	is.NoErr(VerifyAction(&hashingServer{conn}, memFS, files, keep, &result).Run())
+	is.True(result.IsValid())
+}
+
+func TestLocalChecksum(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	is.NoErr(afero.WriteFile(memFS, "hello.txt", []byte("hello"), 0644))
+
+	for algorithm, expected := range map[string]string{
+		HashSHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		HashSHA1:   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		HashMD5:    "5d41402abc4b2a76b9719d911017c592",
+		HashCRC32:  "3610a686",
+	} {
+		sum, err := localChecksum(memFS, "hello.txt", algorithm)
+		is.NoErr(err)
+		is.Equal(expected, sum) // algorithm
+	}
+	_, err := localChecksum(memFS, "hello.txt", "SHA-512")
+	is.True(err != nil)
+}
@@ -0,0 +1,233 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+// Package secrets implements the local credentials store, encrypted with a passphrase.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Environment variables overriding the store location and providing the passphrase.
+const (
+	FileEnvVar       string = "SVELTIN_SECRETS_FILE"
+	PassphraseEnvVar string = "SVELTIN_SECRETS_PASSPHRASE"
+)
+
+// storeVersion is the version of the store file format.
+const storeVersion = 1
+
+// scrypt parameters, as recommended for interactive logins.
+const (
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	minPassBytes = 8
+)
+
+// ErrWrongPassphrase is returned when a value cannot be decrypted with the passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase, the secrets store cannot be unlocked")
+
+// Store is the local credentials store. Names are stored in plain text, values are encrypted
+// with AES-256-GCM using a key derived from the passphrase with scrypt.
+type Store struct {
+	fs      afero.Fs
+	path    string
+	salt    []byte
+	entries map[string]string
+	key     []byte
+}
+
+// storeFile is the struct representing the content of the store file.
+type storeFile struct {
+	Version int               `json:"version"`
+	Salt    string            `json:"salt"`
+	Entries map[string]string `json:"entries"`
+}
+
+// DefaultPath returns the path to the store file: the SVELTIN_SECRETS_FILE environment variable
+// if set, secrets.json within the sveltin folder of the user configuration directory otherwise.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(FileEnvVar); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sveltin", "secrets.json"), nil
+}
+
+// Open reads the store file, an empty store is returned when it does not exist.
+// The store is locked until Unlock is called.
+func Open(fs afero.Fs, path string) (*Store, error) {
+	s := &Store{fs: fs, path: path, entries: map[string]string{}}
+	exists, err := afero.Exists(fs, path)
+	if err != nil || !exists {
+		return s, err
+	}
+
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	var file storeFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("could not parse the secrets store '%s', got error '%s'", path, err.Error())
+	}
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("unsupported secrets store version %d in '%s'", file.Version, path)
+	}
+	if s.salt, err = base64.StdEncoding.DecodeString(file.Salt); err != nil {
+		return nil, fmt.Errorf("could not parse the secrets store '%s', got error '%s'", path, err.Error())
+	}
+	if file.Entries != nil {
+		s.entries = file.Entries
+	}
+	return s, nil
+}
+
+// Path returns the path to the store file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Has returns true if the store has a value for the name. The store does not need to be unlocked.
+func (s *Store) Has(name string) bool {
+	_, ok := s.entries[name]
+	return ok
+}
+
+// Names returns the sorted list of the names within the store.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Unlock derives the encryption key from the passphrase. A wrong passphrase is detected
+// by decrypting one of the values, if any.
+func (s *Store) Unlock(passphrase string) error {
+	if len(passphrase) < minPassBytes {
+		return fmt.Errorf("the passphrase must be at least %d characters long", minPassBytes)
+	}
+	if s.salt == nil {
+		s.salt = make([]byte, saltLength)
+		if _, err := rand.Read(s.salt); err != nil {
+			return err
+		}
+	}
+	key, err := scrypt.Key([]byte(passphrase), s.salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return err
+	}
+	s.key = key
+
+	if names := s.Names(); len(names) > 0 {
+		if _, err := s.Get(names[0]); err != nil {
+			s.key = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the decrypted value for the name.
+func (s *Store) Get(name string) (string, error) {
+	encoded, ok := s.entries[name]
+	if !ok {
+		return "", fmt.Errorf("no secret named '%s' in '%s'", name, s.path)
+	}
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("the secret named '%s' is corrupted", name)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+// Set encrypts and stores the value for the name. Call Save to write the store file.
+func (s *Store) Set(name, value string) error {
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// the name is authenticated, values cannot be swapped between names
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	s.entries[name] = base64.StdEncoding.EncodeToString(sealed)
+	return nil
+}
+
+// Remove deletes the value for the name, it returns false if there was none. Call Save to write the store file.
+func (s *Store) Remove(name string) bool {
+	if !s.Has(name) {
+		return false
+	}
+	delete(s.entries, name)
+	return true
+}
+
+// Save writes the store file, readable by the current user only.
+func (s *Store) Save() error {
+	if s.salt == nil {
+		return errors.New("the secrets store must be unlocked before saving it")
+	}
+	content, err := json.MarshalIndent(storeFile{
+		Version: storeVersion,
+		Salt:    base64.StdEncoding.EncodeToString(s.salt),
+		Entries: s.entries,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(s.fs, s.path, content, 0600); err != nil {
+		return err
+	}
+	return s.fs.Chmod(s.path, 0600)
+}
+
+func (s *Store) cipher() (cipher.AEAD, error) {
+	if s.key == nil {
+		return nil, errors.New("the secrets store is locked")
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/afero"
+)
+
+func TestStore(t *testing.T) {
+	is := is.New(t)
+
+	memFS := afero.NewMemMapFs()
+	store, err := Open(memFS, "config/sveltin/secrets.json")
+	is.NoErr(err)
+	is.Equal(0, len(store.Names()))
+	is.True(store.Set("blog/production", "s3cr3t") != nil) // locked
+	is.True(store.Unlock("short") != nil)
+
+	is.NoErr(store.Unlock("correct horse battery"))
+	is.NoErr(store.Set("blog/production", "s3cr3t"))
+	is.NoErr(store.Set("blog/staging", "st4ging"))
+	is.NoErr(store.Save())
+
+	content, err := afero.ReadFile(memFS, "config/sveltin/secrets.json")
+	is.NoErr(err)
+	is.True(!strings.Contains(string(content), "s3cr3t"))
+	info, err := memFS.Stat("config/sveltin/secrets.json")
+	is.NoErr(err)
+	is.Equal("-rw-------", info.Mode().Perm().String())
+
+	store, err = Open(memFS, "config/sveltin/secrets.json")
+	is.NoErr(err)
+	is.Equal([]string{"blog/production", "blog/staging"}, store.Names())
+	is.True(store.Has("blog/staging"))
+	_, err = store.Get("blog/production")
+	is.True(err != nil) // locked
+
+	is.True(errors.Is(store.Unlock("wrong passphrase"), ErrWrongPassphrase))
+	is.NoErr(store.Unlock("correct horse battery"))
+	value, err := store.Get("blog/production")
+	is.NoErr(err)
+	is.Equal("s3cr3t", value)
+	_, err = store.Get("blog/missing")
+	is.True(err != nil)
+
+	is.True(store.Remove("blog/staging"))
+	is.True(!store.Remove("blog/staging"))
+	is.NoErr(store.Save())
+	store, err = Open(memFS, "config/sveltin/secrets.json")
+	is.NoErr(err)
+	is.Equal([]string{"blog/production"}, store.Names())
+}
+
+func TestStoreDefaultPath(t *testing.T) {
+	is := is.New(t)
+
+	t.Setenv(FileEnvVar, "/tmp/secrets.json")
+	path, err := DefaultPath()
+	is.NoErr(err)
+	is.Equal("/tmp/secrets.json", path)
+}
@@ -8,6 +8,7 @@
 package shell
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -93,6 +94,115 @@ func (s *GitShell) RunSubmodule(repoURL, inpath string, silentMode bool) error {
 	return nil
 }
 
+// RunRemoteGetURL returns the URL of the named remote for the repository within localPath.
+func (s *GitShell) RunRemoteGetURL(localPath, name string) (string, error) {
+	out, err := s.run(localPath, "remote", "get-url", name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RunBranchExists returns true if the branch exists on the remote repository.
+func (s *GitShell) RunBranchExists(repoURL, branch string) (bool, error) {
+	if repoURL == "" || branch == "" {
+		return false, sveltinerr.NewNotValidArgumentsError()
+	}
+	out, err := s.run("", "ls-remote", "--heads", repoURL, branch)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// RunCloneBranch clones the last commit of the branch only.
+func (s *GitShell) RunCloneBranch(repoURL, branch, inpath string) error {
+	if repoURL == "" || branch == "" || inpath == "" {
+		return sveltinerr.NewNotValidArgumentsError()
+	}
+	_, err := s.run("", "clone", "-q", "--depth", "1", "--single-branch", "--branch", branch, repoURL, inpath)
+	return err
+}
+
+// RunInitBranch initializes a repository with the branch, without any history, and the remote repository as origin.
+func (s *GitShell) RunInitBranch(repoURL, branch, inpath string) error {
+	if repoURL == "" || branch == "" || inpath == "" {
+		return sveltinerr.NewNotValidArgumentsError()
+	}
+	if _, err := s.run("", "init", "-q", inpath); err != nil {
+		return err
+	}
+	if _, err := s.run(inpath, "checkout", "-q", "--orphan", branch); err != nil {
+		return err
+	}
+	_, err := s.run(inpath, "remote", "add", "origin", repoURL)
+	return err
+}
+
+// GitChanges is the struct representing the changes staged within a repository.
+type GitChanges struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// IsEmpty returns true if nothing has been changed.
+func (c *GitChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Modified) == 0 && len(c.Deleted) == 0
+}
+
+// RunStageAll stages all the changes within the repository and returns them.
+func (s *GitShell) RunStageAll(localPath string) (*GitChanges, error) {
+	if _, err := s.run(localPath, "add", "-A"); err != nil {
+		return nil, err
+	}
+	out, err := s.run(localPath, "diff", "--cached", "--name-status", "--no-renames")
+	if err != nil {
+		return nil, err
+	}
+	changes := &GitChanges{Added: []string{}, Modified: []string{}, Deleted: []string{}}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "A":
+			changes.Added = append(changes.Added, fields[1])
+		case "D":
+			changes.Deleted = append(changes.Deleted, fields[1])
+		default:
+			changes.Modified = append(changes.Modified, fields[1])
+		}
+	}
+	return changes, nil
+}
+
+// RunCommit commits the staged changes with the message.
+func (s *GitShell) RunCommit(localPath, message string) error {
+	_, err := s.run(localPath, "commit", "-q", "-m", message)
+	return err
+}
+
+// RunPush pushes the branch to the origin remote repository.
+func (s *GitShell) RunPush(localPath, branch string) error {
+	_, err := s.run(localPath, "push", "-q", "origin", branch)
+	return err
+}
+
+// run executes git with the arguments within the folder. On failure, the error reports the git output.
+func (s *GitShell) run(localPath string, args ...string) (string, error) {
+	out, err := s.GetShell().ExecuteInDir(localPath, GitBin, args)
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", sveltinerr.NewExecSystemCommandErrorWithMsg(fmt.Errorf("%s %s: %s", GitBin, strings.Join(args, " "), msg))
+	}
+	return string(out), nil
+}
+
 func cleanGitRepository(inpath string, foldersToRemove []string) error {
 	var err error
 	for _, folder := range foldersToRemove {
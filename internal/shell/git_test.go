@@ -1,6 +1,9 @@
 package shell
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/matryer/is"
@@ -32,3 +35,64 @@ func TestGitClone(t *testing.T) {
 
 	is.NoErr(osFs.RemoveAll(helloWorld.Name))
 }
+
+func TestGitPublishBranch(t *testing.T) {
+	is := is.New(t)
+	if _, err := exec.LookPath(GitBin); err != nil {
+		t.Skip("git not available")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "sveltin")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "sveltin@example.com")
+	}
+
+	tmpDir := t.TempDir()
+	remote := filepath.Join(tmpDir, "remote.git")
+	gitClient := NewGitClient()
+	_, err := gitClient.run("", "init", "-q", "--bare", remote)
+	is.NoErr(err)
+
+	exists, err := gitClient.RunBranchExists(remote, "gh-pages")
+	is.NoErr(err)
+	is.True(!exists)
+
+	// first deploy, the branch is created
+	first := filepath.Join(tmpDir, "first")
+	is.NoErr(gitClient.RunInitBranch(remote, "gh-pages", first))
+	is.NoErr(os.WriteFile(filepath.Join(first, "index.html"), []byte("<h1>Hello</h1>"), 0644))
+	changes, err := gitClient.RunStageAll(first)
+	is.NoErr(err)
+	is.Equal([]string{"index.html"}, changes.Added)
+	is.NoErr(gitClient.RunCommit(first, "Deploy\n\nwith sveltin"))
+	is.NoErr(gitClient.RunPush(first, "gh-pages"))
+
+	exists, err = gitClient.RunBranchExists(remote, "gh-pages")
+	is.NoErr(err)
+	is.True(exists)
+
+	// next deploy, the branch is cloned
+	next := filepath.Join(tmpDir, "next")
+	is.NoErr(gitClient.RunCloneBranch(remote, "gh-pages", next))
+	data, err := os.ReadFile(filepath.Join(next, "index.html"))
+	is.NoErr(err)
+	is.Equal("<h1>Hello</h1>", string(data))
+	changes, err = gitClient.RunStageAll(next)
+	is.NoErr(err)
+	is.True(changes.IsEmpty())
+
+	is.NoErr(os.Remove(filepath.Join(next, "index.html")))
+	is.NoErr(os.WriteFile(filepath.Join(next, "about.html"), []byte("<h1>About</h1>"), 0644))
+	changes, err = gitClient.RunStageAll(next)
+	is.NoErr(err)
+	is.Equal([]string{"about.html"}, changes.Added)
+	is.Equal([]string{"index.html"}, changes.Deleted)
+
+	url, err := gitClient.RunRemoteGetURL(next, "origin")
+	is.NoErr(err)
+	is.Equal(remote, url)
+
+	_, err = gitClient.RunRemoteGetURL(next, "missing")
+	is.True(err != nil)
+}
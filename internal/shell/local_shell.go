@@ -63,3 +63,10 @@ func (s *LocalShell) BackgroundExecute(ctx context.Context, cmdName string, cmdO
 	output, error := wrapperCmd.CombinedOutput()
 	return output, error
 }
+
+// ExecuteInDir runs command with the arguments, as they are, within the folder and returns its combined output.
+func (s *LocalShell) ExecuteInDir(dir string, cmdName string, args []string) ([]byte, error) {
+	cmd := exec.Command(cmdName, args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
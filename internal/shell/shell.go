@@ -14,4 +14,5 @@ import "context"
 type Shell interface {
 	Execute(string, string, bool) error
 	BackgroundExecute(context.Context, string, string, string) ([]byte, error)
+	ExecuteInDir(string, string, []string) ([]byte, error)
 }
@@ -0,0 +1,75 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package tpltypes
+
+import "time"
+
+// ContentEntry is the struct representing the front matter of a resource content.
+type ContentEntry struct {
+	Resource   string
+	Name       string
+	Path       string
+	Layout     string
+	Title      string
+	Author     string
+	Slug       string
+	Headline   string
+	Keywords   []string
+	Tags       []string
+	Categories []string
+	Cover      string
+	Draft      bool
+	Created    time.Time
+	Updated    time.Time
+	// Params holds all the front matter values, including the ones not mapped to a field.
+	Params map[string]interface{}
+}
+
+// LastModified returns the update date of the content, the creation date if not set.
+func (e *ContentEntry) LastModified() time.Time {
+	if e.Updated.IsZero() {
+		return e.Created
+	}
+	return e.Updated
+}
+
+// ContentIndex is the struct representing the front matter of all the resources contents.
+// Entries are sorted by creation date, newest first.
+type ContentIndex struct {
+	Resources []string
+	Entries   map[string][]*ContentEntry
+}
+
+// Get returns the entries for the resource, drafts included.
+func (ci *ContentIndex) Get(resource string) []*ContentEntry {
+	if ci == nil {
+		return nil
+	}
+	return ci.Entries[resource]
+}
+
+// Published returns the entries for the resource not marked as draft.
+func (ci *ContentIndex) Published(resource string) []*ContentEntry {
+	entries := []*ContentEntry{}
+	for _, e := range ci.Get(resource) {
+		if !e.Draft {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Find returns the entry for the resource content, nil if not found.
+func (ci *ContentIndex) Find(resource, name string) *ContentEntry {
+	for _, e := range ci.Get(resource) {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package tpltypes
+
+import "time"
+
+// FeedData is the struct representing the channel and the items of a feed (rss, atom and json).
+type FeedData struct {
+	ID             string
+	Title          string
+	Link           string
+	FeedURL        string
+	Description    string
+	Language       string
+	Copyright      string
+	ManagingEditor string
+	Generator      string
+	Updated        time.Time
+	Items          []*FeedItem
+}
+
+// FeedItem is the struct representing a content within a feed.
+// ID is derived from the base URL, the resource and the slug, it does not change across builds.
+type FeedItem struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Categories  []string
+	Published   time.Time
+	Updated     time.Time
+	Enclosure   *FeedEnclosure
+}
+
+// FeedEnclosure is the struct representing the media attached to a feed item (the content cover).
+type FeedEnclosure struct {
+	URL    string
+	Length int64
+	Type   string
+}
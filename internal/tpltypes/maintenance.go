@@ -0,0 +1,15 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package tpltypes
+
+// MaintenanceData is the struct representing data for the maintenance page shown while deploying.
+type MaintenanceData struct {
+	BaseURL      string
+	Timestamp    string
+	ISOTimestamp string
+}
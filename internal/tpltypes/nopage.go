@@ -11,6 +11,17 @@ package tpltypes
 type NoPageData struct {
 	Data  *ProjectSettings
 	Items *NoPageItems
+	Feed  *FeedData
+	URLs  []*SitemapURL
+}
+
+// SitemapURL is the struct representing an url within the sitemap.
+// LastMod is formatted as W3C datetime, empty if unknown.
+type SitemapURL struct {
+	Loc        string
+	LastMod    string
+	ChangeFreq string
+	Priority   string
 }
 
 // NoPageItems is the struct representing an item
@@ -0,0 +1,14 @@
+/**
+ * Copyright © 2021-present Sveltin contributors <github@sveltin.io>
+ *
+ * Use of this source code is governed by Apache 2.0 license
+ * that can be found in the LICENSE file.
+ */
+
+package tpltypes
+
+// PrecompressData is the struct representing data for the .htaccess rules serving the precompressed files.
+// ContentTypes maps the precompressed file extensions, without the dot, to their content type.
+type PrecompressData struct {
+	ContentTypes map[string]string
+}
@@ -10,14 +10,31 @@ package tpltypes
 
 // EnvProductionData is the struct used to map the env.production file props.
 type EnvProductionData struct {
-	BaseURL         string `mapstructure:"VITE_PUBLIC_BASE_PATH"`
-	FTPHost         string `mapstructure:"FTP_HOST"`
-	FTPPort         int    `mapstructure:"FTP_PORT"`
-	FTPUser         string `mapstructure:"FTP_USER"`
-	FTPPassword     string `mapstructure:"FTP_PASSWORD"`
-	FTPServerFolder string `mapstructure:"FTP_SERVER_FOLDER"`
-	FTPDialTimeout  int    `mapstructure:"FTP_DIAL_TIMEOUT"`
-	FTPEPSVMode     bool   `mapstructure:"FTP_EPSV"`
+	BaseURL                  string `mapstructure:"VITE_PUBLIC_BASE_PATH"`
+	DeployProtocol           string `mapstructure:"DEPLOY_PROTOCOL"`
+	FTPHost                  string `mapstructure:"FTP_HOST"`
+	FTPPort                  int    `mapstructure:"FTP_PORT"`
+	FTPUser                  string `mapstructure:"FTP_USER"`
+	FTPPassword              string `mapstructure:"FTP_PASSWORD"`
+	FTPServerFolder          string `mapstructure:"FTP_SERVER_FOLDER"`
+	FTPDialTimeout           int    `mapstructure:"FTP_DIAL_TIMEOUT"`
+	FTPEPSVMode              bool   `mapstructure:"FTP_EPSV"`
+	FTPTLS                   string `mapstructure:"FTP_TLS"`
+	FTPTLSInsecureSkipVerify bool   `mapstructure:"FTP_TLS_INSECURE_SKIP_VERIFY"`
+	FTPTLSCAFile             string `mapstructure:"FTP_TLS_CA_FILE"`
+	SSHKeyPath               string `mapstructure:"SSH_KEY_PATH"`
+	SSHKeyPassphrase         string `mapstructure:"SSH_KEY_PASSPHRASE"`
+	SSHKnownHostsPath        string `mapstructure:"SSH_KNOWN_HOSTS"`
+	SSHInsecureIgnoreHostKey bool   `mapstructure:"SSH_INSECURE_IGNORE_HOST_KEY"`
+	S3Bucket                 string `mapstructure:"S3_BUCKET"`
+	S3Region                 string `mapstructure:"S3_REGION"`
+	S3Endpoint               string `mapstructure:"S3_ENDPOINT"`
+	S3Prefix                 string `mapstructure:"S3_PREFIX"`
+	S3AccessKeyID            string `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey        string `mapstructure:"S3_SECRET_ACCESS_KEY"`
+	S3SessionToken           string `mapstructure:"S3_SESSION_TOKEN"`
+	GitRemote                string `mapstructure:"GIT_REMOTE"`
+	GitBranch                string `mapstructure:"GIT_BRANCH"`
 }
 
 // ProjectSettings is the struct used to map the sveltin.json file props.
@@ -27,7 +44,9 @@ type ProjectSettings struct {
 	SvelteKit SvelteKitData  `mapstructure:"sveltekit" json:"sveltekit" validate:"required"`
 	Theme     ThemeData      `mapstructure:"theme" json:"theme" validate:"required"`
 	Sitemap   SitemapData    `mapstructure:"sitemap" json:"sitemap" validate:"required"`
+	RSS       RSSData        `mapstructure:"rss" json:"rss,omitempty"`
 	Sveltin   SveltinCLIData `mapstructure:"sveltin" json:"sveltin" validate:"required"`
+	Deploy    DeployData     `mapstructure:"deploy" json:"deploy,omitempty"`
 }
 
 // SvelteKitData is the struct used to map sveltekit config props.
@@ -49,7 +68,83 @@ type SveltinCLIData struct {
 }
 
 // SitemapData is the struct used to map the sitemap props.
+// Resources and Routes override the default values, a route (e.g. "about" or "posts/hello")
+// taking precedence over its resource.
 type SitemapData struct {
-	ChangeFreq string  `mapstructure:"changeFreq" json:"changeFreq" validate:"required,oneof='always' 'hourly' 'daily' 'weekly' 'monthly' 'yearly' 'never'"`
-	Priority   float32 `mapstructure:"priority" json:"priority" validate:"required,numeric"`
+	ChangeFreq string                         `mapstructure:"changeFreq" json:"changeFreq" validate:"required,oneof='always' 'hourly' 'daily' 'weekly' 'monthly' 'yearly' 'never'"`
+	Priority   float32                        `mapstructure:"priority" json:"priority" validate:"required,numeric"`
+	Resources  map[string]SitemapOverrideData `mapstructure:"resources" json:"resources,omitempty" validate:"omitempty,dive"`
+	Routes     map[string]SitemapOverrideData `mapstructure:"routes" json:"routes,omitempty" validate:"omitempty,dive"`
+}
+
+// SitemapOverrideData is the struct used to map the sitemap props for a resource or a route.
+// Empty values keep the default ones.
+type SitemapOverrideData struct {
+	ChangeFreq string   `mapstructure:"changeFreq" json:"changeFreq,omitempty" validate:"omitempty,oneof='always' 'hourly' 'daily' 'weekly' 'monthly' 'yearly' 'never'"`
+	Priority   *float32 `mapstructure:"priority" json:"priority,omitempty" validate:"omitempty,min=0,max=1"`
+}
+
+// RSSData is the struct used to map the rss feed props.
+// Empty values fall back to the ones within the website config file.
+type RSSData struct {
+	Title          string `mapstructure:"title" json:"title,omitempty"`
+	Description    string `mapstructure:"description" json:"description,omitempty"`
+	Language       string `mapstructure:"language" json:"language,omitempty"`
+	Copyright      string `mapstructure:"copyright" json:"copyright,omitempty"`
+	ManagingEditor string `mapstructure:"managingEditor" json:"managingEditor,omitempty"`
+	Limit          int    `mapstructure:"limit" json:"limit,omitempty"`
+	// Resources holds the per-resource feed props, by resource name.
+	Resources map[string]RSSResourceData `mapstructure:"resources" json:"resources,omitempty"`
+}
+
+// RSSResourceData is the struct used to map the props of a per-resource feed.
+// Exclude opts the resource out of the per-resource feeds, its contents are still within the aggregate feed.
+type RSSResourceData struct {
+	Title       string `mapstructure:"title" json:"title,omitempty"`
+	Description string `mapstructure:"description" json:"description,omitempty"`
+	Exclude     bool   `mapstructure:"exclude" json:"exclude,omitempty"`
+}
+
+// DeployData is the struct used to map the deploy props.
+type DeployData struct {
+	Environments map[string]DeployEnvironmentData `mapstructure:"environments" json:"environments,omitempty"`
+	Backups      DeployBackupsData                `mapstructure:"backups" json:"backups,omitempty"`
+	Maintenance  DeployMaintenanceData            `mapstructure:"maintenance" json:"maintenance,omitempty"`
+	Chmod        []string                         `mapstructure:"chmod" json:"chmod,omitempty"`
+}
+
+// DeployMaintenanceData is the struct used to map the maintenance page props for the full deploys.
+// Template is the path to the file overriding the default maintenance page.
+type DeployMaintenanceData struct {
+	Enabled  bool   `mapstructure:"enabled" json:"enabled,omitempty"`
+	Htaccess bool   `mapstructure:"htaccess" json:"htaccess,omitempty"`
+	Template string `mapstructure:"template" json:"template,omitempty"`
+}
+
+// DeployBackupsData is the struct used to map the retention policies for the backup archives.
+// Zero values disable the policy.
+type DeployBackupsData struct {
+	Keep       int    `mapstructure:"keep" json:"keep,omitempty"`
+	MaxAgeDays int    `mapstructure:"maxAgeDays" json:"maxAgeDays,omitempty"`
+	MaxSize    string `mapstructure:"maxSize" json:"maxSize,omitempty"`
+}
+
+// DeployEnvironmentData is the struct used to map the props of a named deploy environment.
+// Not empty values override the ones read from the environment dotenv file.
+type DeployEnvironmentData struct {
+	EnvFile      string   `mapstructure:"envFile" json:"envFile,omitempty"`
+	BaseURL      string   `mapstructure:"baseurl" json:"baseurl,omitempty"`
+	Protocol     string   `mapstructure:"protocol" json:"protocol,omitempty"`
+	Host         string   `mapstructure:"host" json:"host,omitempty"`
+	Port         int      `mapstructure:"port" json:"port,omitempty"`
+	User         string   `mapstructure:"user" json:"user,omitempty"`
+	ServerFolder string   `mapstructure:"serverFolder" json:"serverFolder,omitempty"`
+	Bucket       string   `mapstructure:"bucket" json:"bucket,omitempty"`
+	Region       string   `mapstructure:"region" json:"region,omitempty"`
+	Endpoint     string   `mapstructure:"endpoint" json:"endpoint,omitempty"`
+	Prefix       string   `mapstructure:"prefix" json:"prefix,omitempty"`
+	GitRemote    string   `mapstructure:"gitRemote" json:"gitRemote,omitempty"`
+	GitBranch    string   `mapstructure:"gitBranch" json:"gitBranch,omitempty"`
+	Exclude      []string `mapstructure:"exclude" json:"exclude,omitempty"`
+	Backup       *bool    `mapstructure:"backup" json:"backup,omitempty"`
 }
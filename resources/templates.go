@@ -102,12 +102,21 @@ var ContentFilesMap = EmbeddedFSEntry{
 	"sample": "internal/templates/content/sample.svx.gotxt",
 }
 
-// XMLFilesMap is a map for the xml (sitemap and rss) template files.
+// XMLFilesMap is a map for the xml (sitemap and feeds) template files.
 var XMLFilesMap = EmbeddedFSEntry{
-	"sitemap_static": "internal/templates/xml/sitemap.xml.gotxt",
-	"rss_static":     "internal/templates/xml/rss.xml.gotxt",
-	"sitemap_ssr":    "internal/templates/xml/ssr_sitemap.xml.ts.gotxt",
-	"rss_ssr":        "internal/templates/xml/ssr_rss.xml.ts.gotxt",
+	"sitemap_static":  "internal/templates/xml/sitemap.xml.gotxt",
+	"rss_static":      "internal/templates/xml/rss.xml.gotxt",
+	"atom_static":     "internal/templates/xml/atom.xml.gotxt",
+	"jsonfeed_static": "internal/templates/xml/feed.json.gotxt",
+	"sitemap_ssr":     "internal/templates/xml/ssr_sitemap.xml.ts.gotxt",
+	"rss_ssr":         "internal/templates/xml/ssr_rss.xml.ts.gotxt",
+}
+
+// DeployFilesMap is a map for the template files uploaded by the deploy command.
+var DeployFilesMap = EmbeddedFSEntry{
+	"maintenance":          "internal/templates/deploy/maintenance.html.gotxt",
+	"maintenance_htaccess": "internal/templates/deploy/htaccess.gotxt",
+	"precompress_htaccess": "internal/templates/deploy/precompress.htaccess.gotxt",
 }
 
 //=============================================================================
@@ -73,6 +73,8 @@ func TestSveltinXMLFS(t *testing.T) {
 	is.Equal("internal/templates/xml/sitemap.xml.gotxt", XMLFilesMap["sitemap_static"])
 	is.Equal("internal/templates/xml/ssr_sitemap.xml.ts.gotxt", XMLFilesMap["sitemap_ssr"])
 	is.Equal("internal/templates/xml/rss.xml.gotxt", XMLFilesMap["rss_static"])
+	is.Equal("internal/templates/xml/atom.xml.gotxt", XMLFilesMap["atom_static"])
+	is.Equal("internal/templates/xml/feed.json.gotxt", XMLFilesMap["jsonfeed_static"])
 	is.Equal("internal/templates/xml/ssr_rss.xml.ts.gotxt", XMLFilesMap["rss_ssr"])
 }
 
@@ -9,8 +9,11 @@ package feedbacks
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/sveltinio/sveltin/config"
+	"github.com/sveltinio/sveltin/helpers"
+	"github.com/sveltinio/sveltin/internal/ftpfs"
 	"github.com/sveltinio/sveltin/internal/markup"
 	"github.com/sveltinio/sveltin/internal/tpltypes"
 	"github.com/sveltinio/sveltin/utils"
@@ -96,8 +99,8 @@ func ShowDryRunMessage() {
 	fmt.Println(markup.Bordered(markup.Centered(fmt.Sprintf("%s\n\n%s", markup.Underline("DRY-RUN MODE"), "Nothing will really happen! Just simulating the process."))))
 }
 
-// ShowDeployCommandWarningMessages display a set of useful information for the deploy over FTP process.
-func ShowDeployCommandWarningMessages(isBackup bool) {
+// ShowDeployCommandWarningMessages display a set of useful information for the deploy process.
+func ShowDeployCommandWarningMessages(isBackup, isIncremental, isAtomic bool) {
 	listLogger := logger.NewListLogger()
 	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
 		Timestamp: false,
@@ -110,8 +113,203 @@ func ShowDeployCommandWarningMessages(isBackup bool) {
 	if isBackup {
 		listLogger.Append(logger.WarningLevel, "Create a backup of the existing content on the remote folder")
 	}
-	listLogger.Append(logger.WarningLevel, "Delete existing content except what specified with --exclude or --withExcludeFile flags")
-	listLogger.Append(logger.WarningLevel, "Upload content to the remote folder")
+	switch {
+	case isAtomic:
+		listLogger.Append(logger.WarningLevel, "Upload content to a staging folder next to the remote folder")
+		listLogger.Append(logger.WarningLevel, "Swap the staging folder with the remote folder, keeping the previous content as .sveltin-prev")
+	case isIncremental:
+		listLogger.Append(logger.WarningLevel, "Upload new and changed content to the remote folder")
+		listLogger.Append(logger.WarningLevel, "Delete content no longer existing except what specified with --exclude, --withExcludeFile flags or .sveltinignore")
+	default:
+		listLogger.Append(logger.WarningLevel, "Delete existing content except what specified with --exclude, --withExcludeFile flags or .sveltinignore")
+		listLogger.Append(logger.WarningLevel, "Upload content to the remote folder")
+	}
+	listLogger.Render()
+}
+
+// ShowDeployRollbackWarningMessages display a set of useful information for the deploy rollback command.
+func ShowDeployRollbackWarningMessages(backupName string) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    true,
+		Icons:     true,
+	})
+
+	listLogger.Title("Be aware! The rollback command will perform the following actions")
+	listLogger.Append(logger.WarningLevel, "Delete existing content except what specified with --exclude, --withExcludeFile flags or .sveltinignore")
+	listLogger.Append(logger.WarningLevel, fmt.Sprintf("Upload the content of the backup archive %s to the remote folder", backupName))
+	listLogger.Render()
+}
+
+// ShowDeploySummary display the list of files added, changed and removed by the deploy process.
+func ShowDeploySummary(added, changed, removed []string) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	listLogger.Title(fmt.Sprintf("Deploy summary: %d added, %d changed, %d removed", len(added), len(changed), len(removed)))
+	for _, file := range added {
+		listLogger.Append(logger.SuccessLevel, fmt.Sprintf("%s %s", markup.Green("added  "), file))
+	}
+	for _, file := range changed {
+		listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s %s", markup.Blue("changed"), file))
+	}
+	for _, file := range removed {
+		listLogger.Append(logger.WarningLevel, fmt.Sprintf("%s %s", markup.Amber("removed"), file))
+	}
+	listLogger.Render()
+}
+
+// ShowDeployEnvironments display the list of the configured deploy environments.
+func ShowDeployEnvironments(names []string, envs map[string]tpltypes.EnvProductionData) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	listLogger.Title("Deploy environments")
+	if len(names) == 0 {
+		listLogger.Append(logger.WarningLevel, "No environments found. Add a .env.<name> file or a deploy.environments section to sveltin.json")
+	}
+	for _, name := range names {
+		data, ok := envs[name]
+		if !ok {
+			listLogger.Append(logger.ErrorLevel, fmt.Sprintf("%s %s", markup.Amber(name), "not valid"))
+			continue
+		}
+		protocol := data.DeployProtocol
+		if protocol == "" {
+			protocol = "ftp"
+		}
+		if strings.EqualFold(protocol, ftpfs.ProtocolS3) {
+			listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s s3://%s/%s %s",
+				markup.Green(name), data.S3Bucket, strings.Trim(data.S3Prefix, "/"), markup.Faint(data.BaseURL)))
+			continue
+		}
+		listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s %s://%s@%s:%d/%s %s",
+			markup.Green(name), protocol, data.FTPUser, data.FTPHost, data.FTPPort, strings.TrimPrefix(data.FTPServerFolder, "/"), markup.Faint(data.BaseURL)))
+	}
+	listLogger.Render()
+}
+
+// ShowVerifyResult display the outcome of the comparison between the remote folder and the local build.
+func ShowVerifyResult(title string, result *ftpfs.VerifyResult) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	compared := "sizes"
+	if result.Algorithm != "" {
+		compared = "sizes and " + result.Algorithm + " checksums"
+	}
+	listLogger.Title(fmt.Sprintf("%s: %d files checked (%s)", title, result.Checked, compared))
+	if result.IsValid() {
+		listLogger.Append(logger.SuccessLevel, "The remote folder matches the local build")
+	}
+	for _, file := range result.Missing {
+		listLogger.Append(logger.ErrorLevel, fmt.Sprintf("%s %s", markup.Amber("missing   "), file))
+	}
+	for _, file := range result.Extra {
+		listLogger.Append(logger.WarningLevel, fmt.Sprintf("%s %s", markup.Amber("extra     "), file))
+	}
+	for _, m := range result.Mismatched {
+		listLogger.Append(logger.ErrorLevel, fmt.Sprintf("%s %s %s", markup.Amber("mismatched"), m.Path, markup.Faint(m.Reason)))
+	}
+	listLogger.Render()
+}
+
+// ShowRemoteFiles display the list of the files within the remote folder with their size and modification time.
+func ShowRemoteFiles(title string, files []ftpfs.RemoteFile) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	var totalSize int64
+	for _, f := range files {
+		totalSize += f.Size
+	}
+	listLogger.Title(fmt.Sprintf("%s: %d files, %s", title, len(files), utils.ToHumanBytes(totalSize)))
+	for _, f := range files {
+		listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s %s %s",
+			f.Path, markup.Faint(utils.ToHumanBytes(f.Size)), markup.Faint(f.ModTime.Format("2006-01-02 15:04"))))
+	}
+	listLogger.Render()
+}
+
+// ShowDiskUsage display the size and the number of files for the remote folders.
+func ShowDiskUsage(title string, usage []ftpfs.DirUsage) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	listLogger.Title(title)
+	for _, u := range usage {
+		listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s %s %s",
+			markup.Green(fmt.Sprintf("%10s", utils.ToHumanBytes(u.Size))), u.Path, markup.Faint(fmt.Sprintf("(%d files)", u.Files))))
+	}
+	listLogger.Render()
+}
+
+// ShowBackups display the list of the backup archives with their size and date.
+func ShowBackups(title string, backups []helpers.BackupFile) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	var totalSize int64
+	for _, b := range backups {
+		totalSize += b.Size
+	}
+	listLogger.Title(fmt.Sprintf("%s: %d, %s", title, len(backups), utils.ToHumanBytes(totalSize)))
+	if len(backups) == 0 {
+		listLogger.Append(logger.WarningLevel, "No backup archives found")
+	}
+	for _, b := range backups {
+		listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s %s %s",
+			b.Name, markup.Faint(utils.ToHumanBytes(b.Size)), markup.Faint(b.ModTime.Format("2006-01-02 15:04"))))
+	}
+	listLogger.Render()
+}
+
+// ShowBackupEntries display the list of the files within a backup archive.
+func ShowBackupEntries(name string, entries []helpers.BackupEntry) {
+	listLogger := logger.NewListLogger()
+	listLogger.Logger.Printer.SetPrinterOptions(&logger.PrinterOptions{
+		Timestamp: false,
+		Colors:    true,
+		Labels:    false,
+		Icons:     true,
+	})
+
+	listLogger.Title(fmt.Sprintf("%s: %d files", name, len(entries)))
+	for _, e := range entries {
+		listLogger.Append(logger.InfoLevel, fmt.Sprintf("%s %s", e.Name, markup.Faint(utils.ToHumanBytes(e.Size))))
+	}
 	listLogger.Render()
 }
 
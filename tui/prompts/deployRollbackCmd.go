@@ -0,0 +1,50 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/sveltinio/prompti/choose"
+	"github.com/sveltinio/sveltin/helpers"
+	sveltinerr "github.com/sveltinio/sveltin/internal/errors"
+	"github.com/sveltinio/sveltin/utils"
+)
+
+// SelectBackupHandler if not flag passed, prompts the user to select the backup archive to restore.
+func SelectBackupHandler(backups []helpers.BackupFile, backupFlag string) (helpers.BackupFile, error) {
+	if len(backups) == 0 {
+		return helpers.BackupFile{}, sveltinerr.NewDefaultError(errors.New("no backup archives found. Please, run the deploy command with the --backup flag first"))
+	}
+
+	switch nameLenght := len(backupFlag); {
+	case nameLenght == 0:
+		entries := []list.Item{}
+		for _, b := range backups {
+			entries = append(entries, choose.Item{
+				Name: b.Name,
+				Desc: fmt.Sprintf("%s · %s", b.ModTime.Format("2006-01-02 15:04:05"), utils.ToHumanBytes(b.Size)),
+			})
+		}
+		backupPromptContent := &choose.Config{
+			Title:    "Which backup do you want to restore?",
+			ErrorMsg: "Please, select a backup archive.",
+		}
+		result, err := choose.Run(backupPromptContent, entries)
+		if err != nil {
+			return helpers.BackupFile{}, err
+		}
+		return findBackup(backups, result)
+	default:
+		return findBackup(backups, backupFlag)
+	}
+}
+
+func findBackup(backups []helpers.BackupFile, name string) (helpers.BackupFile, error) {
+	for _, b := range backups {
+		if b.Name == name || b.Path == name {
+			return b, nil
+		}
+	}
+	return helpers.BackupFile{}, sveltinerr.NewFileNotFoundError(name)
+}
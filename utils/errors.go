@@ -11,6 +11,7 @@ package utils
 import (
 	"fmt"
 	"log"
+	"os"
 )
 
 // ExitIfError panics on os.Exit(1) if error.
@@ -21,6 +22,15 @@ func ExitIfError(err error) {
 	log.Fatalf("\x1b[31;1m✘ %s\x1b[0m\n", fmt.Sprintf("error: %s", err))
 }
 
+// ExitIfErrorWithCode prints the error and exits with the given code if error.
+func ExitIfErrorWithCode(err error, code int) {
+	if err == nil {
+		return
+	}
+	log.Printf("\x1b[31;1m✘ %s\x1b[0m\n", fmt.Sprintf("error: %s", err))
+	os.Exit(code)
+}
+
 // IsError returns true if error is not nil.
 // If showMessage is true it prints out a warning with the error message.
 func IsError(err error, showMessage bool) bool {
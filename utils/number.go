@@ -1,5 +1,11 @@
 package utils
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // PlusOne adds one to the integer parameter.
 func PlusOne(x int) int {
 	return x + 1
@@ -14,3 +20,40 @@ func MinusOne(x int) int {
 func Sum(x int, y int) int {
 	return x + y
 }
+
+// ToHumanBytes returns the size in bytes as human readable string (e.g. 1.5 KiB).
+func ToHumanBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseHumanBytes returns the size in bytes for a human readable string (e.g. 500MB, 1.5 GiB).
+// Units are powers of 1024, KB and KiB are the same.
+func ParseHumanBytes(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		if exp := strings.IndexByte("KMGTPE", s[n-1]); exp >= 0 {
+			for i := 0; i <= exp; i++ {
+				multiplier *= 1024
+			}
+			s = s[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("'%s' is not a valid size, expected a value like 500MB or 1.5GiB", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
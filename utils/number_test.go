@@ -12,4 +12,21 @@ func TestNumberUtils(t *testing.T) {
 	is.Equal(2, PlusOne(1))
 	is.Equal(3, Sum(1, 2))
 	is.Equal(4, MinusOne(5))
+	is.Equal("512 B", ToHumanBytes(512))
+	is.Equal("1.5 KiB", ToHumanBytes(1536))
+	is.Equal("2.0 MiB", ToHumanBytes(2*1024*1024))
+
+	for input, expected := range map[string]int64{
+		"512":     512,
+		"512B":    512,
+		"1.5 KiB": 1536,
+		"2mb":     2 * 1024 * 1024,
+		"1G":      1024 * 1024 * 1024,
+	} {
+		size, err := ParseHumanBytes(input)
+		is.NoErr(err)
+		is.Equal(expected, size) // input
+	}
+	_, err := ParseHumanBytes("lots")
+	is.True(err != nil)
 }
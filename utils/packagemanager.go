@@ -86,6 +86,15 @@ func RetrieveProjectName(appFS afero.Fs, pathToPkgJSON string) (string, error) {
 	return "", sveltinerr.NewProjectNameNotFoundError()
 }
 
+// RetrieveProjectVersion returns the project version as set by the package.json file, empty if not set.
+func RetrieveProjectVersion(appFS afero.Fs, pathToPkgJSON string) (string, error) {
+	pkgFileContent, err := afero.ReadFile(appFS, pathToPkgJSON)
+	if err != nil {
+		return "", err
+	}
+	return npmc.Parse(pkgFileContent).Version, nil
+}
+
 // RetrievePackageManagerFromPkgJSON returns NPMClient struct parsing the package.json file.
 func RetrievePackageManagerFromPkgJSON(appFS afero.Fs, pathToPkgJSON string) (npmc.NPMClient, error) {
 	pkgFileContent, err := afero.ReadFile(appFS, pathToPkgJSON)